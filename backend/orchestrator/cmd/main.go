@@ -4,33 +4,77 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/api"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/blob"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/jobview"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/scheduler"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/webhook"
 )
 
 func main() {
 	// Load .env file if it exists (ignore error if not found)
 	_ = godotenv.Load()
-	db, err := store.New()
+	db, err := store.New(jobview.Marshal)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	jobQueue := queue.New(os.Getenv("REDIS_URL"))
-	handler := api.NewHandler(db, jobQueue)
+	blobs, err := blobStore()
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+
+	handler := api.NewHandler(db, jobQueue, blobs, resultMaxBytes())
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
 
 	corsHandler := api.CorsMiddleware(mux)
 
-	monitor := scheduler.NewWorkerMonitor(db)
+	monitor := scheduler.NewWorkerMonitor(db, jobQueue)
 	go monitor.Start()
 
+	sweeper := scheduler.NewTimeoutSweeper(db, jobQueue)
+	go sweeper.Start()
+
+	promoter := scheduler.NewDelayedJobPromoter(jobQueue)
+	go promoter.Start()
+
+	cronScheduler := scheduler.NewCronScheduler(db, jobQueue)
+	go cronScheduler.Start()
+
+	rewaker := scheduler.NewPendingJobRewaker(db, jobQueue)
+	go rewaker.Start()
+
+	dispatcher := webhook.NewDispatcher(db, nil)
+	go dispatcher.Start()
+
 	log.Println("Orchestrator listening on :8080")
 	log.Fatal(http.ListenAndServe(":8080", corsHandler))
 }
+
+// resultMaxBytes reads RESULT_MAX_BYTES, the largest job result kept in
+// Postgres. It defaults to 64 KiB.
+func resultMaxBytes() int {
+	n, err := strconv.Atoi(os.Getenv("RESULT_MAX_BYTES"))
+	if err != nil || n < 0 {
+		return 64 << 10
+	}
+	return n
+}
+
+// blobStore opens the store for results over RESULT_MAX_BYTES from
+// RESULT_BLOB_DIR. Without it larger results fail their job.
+func blobStore() (blob.Store, error) {
+	dir := os.Getenv("RESULT_BLOB_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+	return blob.NewFileStore(dir)
+}
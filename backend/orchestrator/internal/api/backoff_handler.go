@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/backoff"
+)
+
+// jobTypeFromPath extracts the job type from /job-types/{type}/backoff.
+func jobTypeFromPath(path string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path, "/job-types/"), "/backoff")
+}
+
+// GetTypeBackoff returns the retry backoff policy of a job type. Types
+// without one of their own report the default policy.
+func (h *Handler) GetTypeBackoff(w http.ResponseWriter, r *http.Request) {
+	jobType := jobTypeFromPath(r.URL.Path)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	policy, err := h.store.GetTypeBackoff(ctx, jobType)
+	if err != nil {
+		http.Error(w, "Failed to fetch backoff policy", http.StatusInternalServerError)
+		return
+	}
+
+	isDefault := policy == nil
+	if isDefault {
+		policy = &backoff.Default
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"type":    jobType,
+		"backoff": policy,
+		"default": isDefault,
+	})
+}
+
+// SetTypeBackoff sets the retry backoff policy of a job type. Jobs that were
+// submitted with their own policy keep it.
+func (h *Handler) SetTypeBackoff(w http.ResponseWriter, r *http.Request) {
+	jobType := jobTypeFromPath(r.URL.Path)
+	if jobType == "" {
+		http.Error(w, "Invalid job type", http.StatusBadRequest)
+		return
+	}
+
+	var policy backoff.Policy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.store.SetTypeBackoff(ctx, jobType, policy); err != nil {
+		http.Error(w, "Failed to set backoff policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"type":    jobType,
+		"backoff": policy,
+		"default": false,
+	})
+}
+
+// DeleteTypeBackoff puts a job type back on the default policy.
+func (h *Handler) DeleteTypeBackoff(w http.ResponseWriter, r *http.Request) {
+	jobType := jobTypeFromPath(r.URL.Path)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	found, err := h.store.DeleteTypeBackoff(ctx, jobType)
+	if err != nil {
+		http.Error(w, "Failed to delete backoff policy", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Backoff policy not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// maxBatchSize caps the number of jobs accepted by one POST /jobs/batch.
+const maxBatchSize = 5000
+
+// batchJobResult is the outcome of one item of a batch, in request order.
+// Items that failed validation or depend on an unknown job carry only Error; Replayed marks items whose
+// idempotency key matched an existing job.
+type batchJobResult struct {
+	ID       string `json:"id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Replayed bool   `json:"replayed,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type batchJobResponse struct {
+	Created  int              `json:"created"`
+	Replayed int              `json:"replayed"`
+	Invalid  int              `json:"invalid"`
+	Results  []batchJobResult `json:"results"`
+}
+
+// CreateJobBatch creates many jobs in one request. Invalid items are
+// reported individually and don't stop the valid ones, which are inserted in
+// a single transaction and woken up with one Redis round trip.
+func (h *Handler) CreateJobBatch(w http.ResponseWriter, r *http.Request) {
+	var reqs []createJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(reqs) == 0 {
+		http.Error(w, "batch must contain at least one job", http.StatusBadRequest)
+		return
+	}
+	if len(reqs) > maxBatchSize {
+		http.Error(w, fmt.Sprintf("batch must not contain more than %d jobs", maxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	resp := batchJobResponse{Results: make([]batchJobResult, len(reqs))}
+
+	var jobs []*store.JobCreate
+	var positions []int
+	for i, req := range reqs {
+		job, err := newJob(req)
+		if err != nil {
+			resp.Results[i].Error = err.Error()
+			resp.Invalid++
+			continue
+		}
+		jobs = append(jobs, job)
+		positions = append(positions, i)
+	}
+
+	if len(jobs) > 0 {
+		// a full batch is a lot more work than a single job
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		created, rejected, err := h.store.CreateJobs(ctx, jobs)
+		if err != nil {
+			http.Error(w, "Failed to create jobs", http.StatusInternalServerError)
+			return
+		}
+
+		var wakeups []queue.Wakeup
+		for k, job := range jobs {
+			if rejected[k] != nil {
+				resp.Results[positions[k]].Error = rejected[k].Error()
+				resp.Invalid++
+				continue
+			}
+			resp.Results[positions[k]] = batchJobResult{
+				ID:       job.ID.String(),
+				Status:   job.Status,
+				Replayed: !created[k],
+			}
+			if !created[k] {
+				resp.Replayed++
+				continue
+			}
+			resp.Created++
+			if job.Status != "PENDING" {
+				continue
+			}
+			wakeups = append(wakeups, queue.Wakeup{
+				JobID:    job.ID.String(),
+				Queue:    job.Queue,
+				Priority: job.Priority,
+				RunAt:    job.RunAt,
+			})
+		}
+
+		// the jobs are committed at this point, so a failed enqueue is not
+		// reported to the client: the rewaker wakes them up shortly after
+		if err := h.queue.EnqueueBatch(ctx, wakeups, now); err != nil {
+			log.Println("Failed to enqueue job batch:", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
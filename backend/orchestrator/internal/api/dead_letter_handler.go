@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 1000
+)
+
+type DeadLetterGroupDTO struct {
+	Type           string    `json:"type"`
+	ErrorSignature string    `json:"error_signature"`
+	Count          int       `json:"count"`
+	FirstSeen      time.Time `json:"first_seen"`
+	LastSeen       time.Time `json:"last_seen"`
+	SampleJobID    string    `json:"sample_job_id"`
+	SampleError    *string   `json:"sample_error"`
+}
+
+// purgeDeadLettersRequest is the body of POST /dead-letters/purge. At least
+// one of Type and ErrorSignature must be set.
+type purgeDeadLettersRequest struct {
+	Type           string `json:"type"`
+	ErrorSignature string `json:"error_signature"`
+	Limit          int    `json:"limit"`
+}
+
+// ListDeadLetters groups the DEAD jobs by type and normalised error, largest
+// group first. ?type= narrows it to one job type and ?limit= caps the number
+// of groups.
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := defaultDeadLetterLimit
+
+	if l := query.Get("limit"); l != "" {
+		lim, err := strconv.Atoi(l)
+		if err == nil && lim > 0 {
+			limit = min(lim, maxDeadLetterLimit)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	groups, total, err := h.store.ListDeadLetterGroups(ctx, query.Get("type"), limit)
+	if err != nil {
+		http.Error(w, "Failed to fetch dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]DeadLetterGroupDTO, 0, len(groups))
+	for _, g := range groups {
+		resp = append(resp, DeadLetterGroupDTO{
+			Type:           g.Type,
+			ErrorSignature: g.ErrorSignature,
+			Count:          g.Count,
+			FirstSeen:      g.FirstSeen,
+			LastSeen:       g.LastSeen,
+			SampleJobID:    g.SampleJobID.String(),
+			SampleError:    g.SampleError,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"total":  total,
+		"groups": resp,
+	})
+}
+
+// PurgeDeadLetters deletes DEAD jobs of a type and/or error signature, up to
+// a limit per request.
+func (h *Handler) PurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	var req purgeDeadLettersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Type == "" && req.ErrorSignature == "" {
+		http.Error(w, "type or error_signature is required", http.StatusBadRequest)
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = maxDeadLetterLimit
+	}
+	if req.Limit > maxDeadLetterLimit {
+		http.Error(w, fmt.Sprintf("limit must not exceed %d", maxDeadLetterLimit), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	n, err := h.store.PurgeDeadLetters(ctx, store.DeadLetterFilter{
+		Type:           req.Type,
+		ErrorSignature: req.ErrorSignature,
+	}, req.Limit)
+	if err != nil {
+		http.Error(w, "Failed to purge dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{
+		"purged": n,
+	})
+}
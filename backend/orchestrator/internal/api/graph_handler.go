@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// maxGraphNodes caps the size of a dependency graph response.
+const maxGraphNodes = 1000
+
+type graphNodeDTO struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type graphEdgeDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GetJobGraph returns the dependency graph around a job: every job it
+// depends on, directly or not, every job depending on it, and the edges
+// between them, pointing from parent to dependent.
+func (h *Handler) GetJobGraph(w http.ResponseWriter, r *http.Request) {
+	jobId, err := jobIDFromPath(r.URL.Path, "/graph")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	graph, err := h.store.GetJobGraph(ctx, jobId, maxGraphNodes)
+	if err != nil {
+		http.Error(w, "Failed to fetch job graph", http.StatusInternalServerError)
+		return
+	}
+	if graph == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	nodes := make([]graphNodeDTO, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		nodes[i] = graphNodeDTO{ID: n.ID.String(), Type: n.Type, Status: n.Status}
+	}
+	edges := make([]graphEdgeDTO, len(graph.Edges))
+	for i, e := range graph.Edges {
+		edges[i] = graphEdgeDTO{From: e.From.String(), To: e.To.String()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"job_id":    jobId.String(),
+		"nodes":     nodes,
+		"edges":     edges,
+		"truncated": graph.Truncated,
+	})
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// groupRequest is the body of POST /groups: up to maxBatchSize jobs and an
+// optional callback job, run once all of them have finished.
+type groupRequest struct {
+	Name     string             `json:"name"`
+	Jobs     []createJobRequest `json:"jobs"`
+	Callback *createJobRequest  `json:"callback"`
+}
+
+type groupDTO struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Total         int        `json:"total"`
+	Pending       int        `json:"pending"`
+	Succeeded     int        `json:"succeeded"`
+	Dead          int        `json:"dead"`
+	Cancelled     int        `json:"cancelled"`
+	Complete      bool       `json:"complete"`
+	CallbackJobID *string    `json:"callback_job_id"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// newGroup validates req and turns it into a group. Unlike a batch, a group
+// is all or nothing, so any invalid job rejects the whole request. The
+// returned error is meant for the client.
+func newGroup(req groupRequest) (*store.GroupCreate, error) {
+	if len(req.Jobs) == 0 {
+		return nil, errors.New("a group needs at least one job")
+	}
+	if len(req.Jobs) > maxBatchSize {
+		return nil, fmt.Errorf("a group must not contain more than %d jobs", maxBatchSize)
+	}
+
+	// a replayed job would belong to another group, or to none
+	g := &store.GroupCreate{ID: uuid.New(), Name: req.Name}
+	for i, jr := range req.Jobs {
+		if jr.IdempotencyKey != "" {
+			return nil, fmt.Errorf("job %d: idempotency keys are not supported in groups", i)
+		}
+		job, err := newJob(jr)
+		if err != nil {
+			return nil, fmt.Errorf("job %d: %w", i, err)
+		}
+		g.Jobs = append(g.Jobs, job)
+	}
+
+	if req.Callback != nil {
+		if req.Callback.IdempotencyKey != "" || len(req.Callback.DependsOn) > 0 {
+			return nil, errors.New("callback: idempotency keys and dependencies are not supported")
+		}
+		job, err := newJob(*req.Callback)
+		if err != nil {
+			return nil, fmt.Errorf("callback: %w", err)
+		}
+		g.Callback = job
+	}
+	return g, nil
+}
+
+// CreateGroup creates a group of jobs whose progress is tracked as a whole.
+func (h *Handler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req groupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	g, err := newGroup(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	released, err := h.store.CreateGroup(ctx, g)
+	if errors.Is(err, store.ErrUnknownDependency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create group", http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]string, len(g.Jobs))
+	var wakeups []queue.Wakeup
+	for i, job := range g.Jobs {
+		ids[i] = job.ID.String()
+		if job.Status == "PENDING" {
+			wakeups = append(wakeups, queue.Wakeup{
+				JobID:    ids[i],
+				Queue:    job.Queue,
+				Priority: job.Priority,
+				RunAt:    job.RunAt,
+			})
+		}
+	}
+	for _, e := range released {
+		wakeups = append(wakeups, queue.Wakeup{JobID: e.ID.String(), Queue: e.Queue, Priority: e.Priority, RunAt: e.RunAt})
+	}
+
+	// the jobs are committed at this point; the rewaker covers a failed
+	// enqueue
+	if err := h.queue.EnqueueBatch(ctx, wakeups, now); err != nil {
+		log.Println("Failed to enqueue group jobs:", err)
+	}
+
+	var callbackID *string
+	if g.Callback != nil {
+		id := g.Callback.ID.String()
+		callbackID = &id
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":              g.ID.String(),
+		"name":            g.Name,
+		"total":           len(g.Jobs),
+		"callback_job_id": callbackID,
+		"job_ids":         ids,
+	})
+}
+
+// GetGroup returns the progress counters of a group.
+func (h *Handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/groups/"))
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	g, err := h.store.GetGroup(ctx, id)
+	if err != nil {
+		http.Error(w, "Failed to fetch group", http.StatusInternalServerError)
+		return
+	}
+	if g == nil {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	dto := groupDTO{
+		ID:          g.ID.String(),
+		Name:        g.Name,
+		Total:       g.Total,
+		Pending:     g.Pending,
+		Succeeded:   g.Succeeded,
+		Dead:        g.Dead,
+		Cancelled:   g.Cancelled,
+		Complete:    g.CompletedAt != nil,
+		CompletedAt: g.CompletedAt,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+	if g.CallbackJobID != nil {
+		cid := g.CallbackJobID.String()
+		dto.CallbackJobID = &cid
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto)
+}
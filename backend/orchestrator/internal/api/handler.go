@@ -2,13 +2,22 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/backoff"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/blob"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/jobview"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
 )
@@ -16,21 +25,63 @@ import (
 type Handler struct {
 	store *store.Store
 	queue *queue.Queue
+
+	// job results larger than resultMaxBytes go to blobs; without a blob
+	// store they fail the job instead
+	blobs          blob.Store
+	resultMaxBytes int
 }
 
-func NewHandler(store *store.Store, queue *queue.Queue) *Handler {
+func NewHandler(store *store.Store, queue *queue.Queue, blobs blob.Store, resultMaxBytes int) *Handler {
 	return &Handler{
-		store: store,
-		queue: queue,
+		store:          store,
+		queue:          queue,
+		blobs:          blobs,
+		resultMaxBytes: resultMaxBytes,
 	}
 }
 
-// defines how our job creation request looks like
+// defaultTimeoutSeconds applies when a job is submitted without a timeout,
+// matching the column default on the jobs table.
+const defaultTimeoutSeconds = 30
+
+// queueNamePattern restricts queue names to characters that are safe in
+// Redis keys.
+var queueNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// maxIdempotencyKeyLength bounds client-chosen idempotency keys.
+const maxIdempotencyKeyLength = 255
+
+// maxDependencies bounds the number of jobs a single job can depend on.
+const maxDependencies = 100
+
+// maxCallbackURLLength bounds completion webhook URLs.
+const maxCallbackURLLength = 2048
+
+// defines how our job creation request looks like.
+// Queue routes the job to the workers subscribed to it (default "default").
+// Priority orders assignment: higher values run first, 0 is the default and
+// negative values yield to everything else. RunAt and DelaySeconds are
+// mutually exclusive ways to hold a job back; without either it is runnable
+// immediately. IdempotencyKey may also be sent as the Idempotency-Key header;
+// resubmitting a key returns the job first created with it. Backoff overrides
+// the retry delay policy of the job's type. A job with DependsOn stays BLOCKED
+// until all of those jobs have succeeded. CallbackURL receives the job's final
+// state once it reaches SUCCESS or DEAD, signed with CallbackSecret if set.
 type createJobRequest struct {
 	Type           string          `json:"type"`
 	Payload        json.RawMessage `json:"payload"`
 	MaxRetries     int             `json:"max_retries"`
 	TimeoutSeconds int             `json:"timeout_seconds"`
+	Queue          string          `json:"queue"`
+	Priority       int             `json:"priority"`
+	RunAt          *time.Time      `json:"run_at"`
+	DelaySeconds   int             `json:"delay_seconds"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	Backoff        *backoff.Policy `json:"backoff"`
+	DependsOn      []string        `json:"depends_on"`
+	CallbackURL    string          `json:"callback_url"`
+	CallbackSecret string          `json:"callback_secret"`
 }
 
 type JobDTO struct {
@@ -38,22 +89,103 @@ type JobDTO struct {
 	Type       string    `json:"type"`
 	Status     string    `json:"status"`
 	RetryCount int       `json:"retry_count"`
-	WorkerID   string   `json:"worker_id"`
+	Queue      string    `json:"queue"`
+	Priority   int       `json:"priority"`
+	WorkerID   string    `json:"worker_id"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
-type JobDetailDTO struct {
-	ID             string          `json:"id"`
-	Type           string          `json:"type"`
-	Payload        json.RawMessage `json:"payload"`
-	Status         string          `json:"status"`
-	RetryCount     int             `json:"retry_count"`
-	MaxRetries     int             `json:"max_retries"`
-	TimeoutSeconds int             `json:"timeout_seconds"`
-	WorkerID       *string         `json:"worker_id"`
-	Error          *string         `json:"error"`
-	CreatedAt      time.Time       `json:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at"`
+// newJob validates req and turns it into a PENDING job, filling in defaults.
+// The returned error is meant for the client.
+func newJob(req createJobRequest) (*store.JobCreate, error) {
+	if req.Type == "" {
+		return nil, errors.New("type is required")
+	}
+	if len(req.Payload) == 0 || !json.Valid(req.Payload) {
+		return nil, errors.New("payload must be valid JSON")
+	}
+
+	if req.TimeoutSeconds <= 0 {
+		req.TimeoutSeconds = defaultTimeoutSeconds
+	}
+
+	if req.Queue == "" {
+		req.Queue = store.DefaultQueue
+	}
+	if !queueNamePattern.MatchString(req.Queue) {
+		return nil, errors.New("invalid queue name")
+	}
+
+	if req.RunAt != nil && req.DelaySeconds != 0 {
+		return nil, errors.New("run_at and delay_seconds are mutually exclusive")
+	}
+	if req.DelaySeconds < 0 {
+		return nil, errors.New("delay_seconds must not be negative")
+	}
+
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, errors.New("idempotency key is too long")
+	}
+
+	if req.Backoff != nil {
+		if err := req.Backoff.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(req.DependsOn) > maxDependencies {
+		return nil, fmt.Errorf("a job can depend on at most %d jobs", maxDependencies)
+	}
+	var dependsOn []uuid.UUID
+	for _, s := range req.DependsOn {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependency id %q", s)
+		}
+		dependsOn = append(dependsOn, id)
+	}
+
+	if req.CallbackURL != "" {
+		if err := validateCallbackURL(req.CallbackURL); err != nil {
+			return nil, err
+		}
+	} else if req.CallbackSecret != "" {
+		return nil, errors.New("callback_secret requires a callback_url")
+	}
+
+	var runAt time.Time
+	if req.RunAt != nil {
+		runAt = *req.RunAt
+	}
+
+	return &store.JobCreate{
+		ID:             uuid.New(),
+		Type:           req.Type,
+		Payload:        req.Payload,
+		Status:         "PENDING",
+		MaxRetries:     req.MaxRetries,
+		TimeoutSeconds: req.TimeoutSeconds,
+		RunAt:          runAt,
+		Delay:          time.Duration(req.DelaySeconds) * time.Second,
+		Queue:          req.Queue,
+		Priority:       req.Priority,
+		IdempotencyKey: req.IdempotencyKey,
+		Backoff:        req.Backoff,
+		DependsOn:      dependsOn,
+		CallbackURL:    req.CallbackURL,
+		CallbackSecret: req.CallbackSecret,
+	}, nil
+}
+
+func validateCallbackURL(raw string) error {
+	if len(raw) > maxCallbackURLLength {
+		return errors.New("callback_url is too long")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("callback_url must be an absolute http or https URL")
+	}
+	return nil
 }
 
 func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
@@ -65,13 +197,20 @@ func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job := &store.JobCreate{
-		ID:             uuid.New(),
-		Type:           req.Type,
-		Payload:        req.Payload,
-		Status:         "PENDING",
-		MaxRetries:     req.MaxRetries,
-		TimeoutSeconds: req.TimeoutSeconds,
+	key := r.Header.Get("Idempotency-Key")
+	if key != "" && req.IdempotencyKey != "" && key != req.IdempotencyKey {
+		http.Error(w, "Idempotency-Key header and idempotency_key differ", http.StatusBadRequest)
+		return
+	}
+	if key != "" {
+		req.IdempotencyKey = key
+	}
+
+	now := time.Now()
+	job, err := newJob(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Creates a new context with a 3-second timeout derived from the HTTP request's context.
@@ -80,13 +219,39 @@ func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	err = h.store.CreateJob(ctx, job)
+	created, err := h.store.CreateJob(ctx, job)
+	if errors.Is(err, store.ErrUnknownDependency) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to create job", http.StatusInternalServerError)
 		return
 	}
 
-	err = h.queue.Enqueue(ctx, job.ID.String())
+	// a replayed key was enqueued by the first request; if that request
+	// failed before enqueueing, the rewaker picks the job up. Jobs that are
+	// not PENDING, because they wait on or failed with their dependencies,
+	// are not enqueued at all.
+	if !created || job.Status != "PENDING" {
+		if !created {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"id":     job.ID.String(),
+			"status": job.Status,
+		})
+		return
+	}
+
+	// jobs due in the future wait in the scheduled set until the promoter
+	// moves them onto the queue
+	if job.RunAt.After(now) {
+		err = h.queue.Schedule(ctx, job.Queue, job.ID.String(), job.Priority, job.RunAt)
+	} else {
+		err = h.queue.Enqueue(ctx, job.Queue, job.ID.String(), job.Priority)
+	}
 	if err != nil {
 		http.Error(w, "Failed to enqueue job", http.StatusInternalServerError)
 		return
@@ -124,16 +289,41 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	filter, err := parseJobFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var cursor *store.JobCursor
+	if c := query.Get("cursor"); c != "" {
+		cursor, err = decodeJobCursor(c)
+		if err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		offset = 0
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	jobs, err := h.store.ListJobs(ctx, limit, offset)
+	// one extra row tells whether there is a next page
+	jobs, err := h.store.ListJobs(ctx, filter, cursor, limit+1, offset)
 
 	if err != nil {
 		http.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
 		return
 	}
 
+	var nextCursor *string
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+		last := jobs[limit-1]
+		c := encodeJobCursor(store.JobCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		nextCursor = &c
+	}
+
 	var response []JobDTO
 
 	for _, job := range jobs {
@@ -142,25 +332,125 @@ func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
 			Type:       job.Type,
 			Status:     job.Status,
 			RetryCount: job.RetryCount,
+			Queue:      job.Queue,
+			Priority:   job.Priority,
 			CreatedAt:  job.CreatedAt,
-			WorkerID:   job.WorkerID.String(),
+		}
+		if job.WorkerID != nil {
+			dto.WorkerID = job.WorkerID.String()
 		}
 		response = append(response, dto)
 	}
 
 	resp := map[string]interface{}{
-		"jobs":   response,
-		"limit":  limit,
-		"offset": offset,
+		"jobs":        response,
+		"limit":       limit,
+		"offset":      offset,
+		"next_cursor": nextCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// encodeJobCursor turns a list position into the opaque next_cursor handed
+// to clients.
+func encodeJobCursor(c store.JobCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeJobCursor(s string) (*store.JobCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, err
+	}
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store.JobCursor{CreatedAt: createdAt, ID: jobID}, nil
+}
+
+// jobStatuses are the values of the job_status enum.
+var jobStatuses = map[string]bool{
+	"PENDING":   true,
+	"RUNNING":   true,
+	"SUCCESS":   true,
+	"FAILED":    true,
+	"RETRYING":  true,
+	"DEAD":      true,
+	"CANCELLED": true,
+	"BLOCKED":   true,
+}
+
+// parseJobFilter reads the ListJobs filters from the query string: status
+// (comma-separated or repeated), type, worker_id, created_after,
+// created_before, updated_after, updated_before (RFC 3339) and error.
+func parseJobFilter(query url.Values) (store.JobFilter, error) {
+	var filter store.JobFilter
+
+	for _, v := range query["status"] {
+		for _, status := range strings.Split(v, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if status == "" {
+				continue
+			}
+			if !jobStatuses[status] {
+				return filter, fmt.Errorf("invalid status %q", status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	filter.Type = query.Get("type")
+	filter.ErrorContains = query.Get("error")
+
+	if v := query.Get("worker_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("invalid worker_id")
+		}
+		filter.WorkerID = &id
+	}
+
+	times := []struct {
+		param string
+		dst   **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+	}
+	for _, t := range times {
+		v := query.Get(t.param)
+		if v == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s, expected an RFC 3339 timestamp", t.param)
+		}
+		*t.dst = &ts
+	}
+
+	return filter, nil
+}
+
 func (h *Handler) GetJobDetail(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/jobs/")
-	jobId, err := uuid.Parse(idStr)
+	jobId, err := jobIDFromPath(r.URL.Path, "")
 	if err != nil {
 		http.Error(w, "Invalid job ID", http.StatusBadRequest)
 		return
@@ -179,30 +469,107 @@ func (h *Handler) GetJobDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var workerID *string
-	if job.WorkerID != nil {
-		wid := job.WorkerID.String()
-		workerID = &wid
+	attempts, err := h.store.ListJobAttempts(ctx, jobId)
+	if err != nil {
+		http.Error(w, "Failed to fetch job attempts", http.StatusInternalServerError)
+		return
 	}
 
-	dto := JobDetailDTO{
-		ID:             job.ID.String(),
-		Type:           job.Type,
-		Payload:        job.Payload,
-		Status:         job.Status,
-		RetryCount:     job.RetryCount,
-		MaxRetries:     job.MaxRetries,
-		TimeoutSeconds: job.TimeoutSeconds,
-		WorkerID:       workerID,
-		Error:          job.Error,
-		CreatedAt:      job.CreatedAt,
-		UpdatedAt:      job.UpdatedAt,
-	}
+	dto := jobview.NewDetail(job, attempts)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(dto)
 }
 
+func (h *Handler) ListJobAttempts(w http.ResponseWriter, r *http.Request) {
+	jobId, err := jobIDFromPath(r.URL.Path, "/attempts")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	attempts, err := h.store.ListJobAttempts(ctx, jobId)
+	if err != nil {
+		http.Error(w, "Failed to fetch job attempts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"attempts": jobview.NewAttempts(attempts),
+	})
+}
+
+// CancelJob stops a job. PENDING jobs are cancelled immediately; for RUNNING
+// jobs the request is accepted and passed on to the worker with its next
+// heartbeat, after which the worker reports the job as CANCELLED.
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobId, err := jobIDFromPath(r.URL.Path, "/cancel")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	status, released, err := h.store.CancelJob(ctx, jobId)
+	if err != nil {
+		http.Error(w, "Failed to cancel job", http.StatusInternalServerError)
+		return
+	}
+
+	// group callbacks released by the cancellation; the rewaker covers a
+	// failed enqueue
+	if err := h.enqueueEntries(ctx, released); err != nil {
+		log.Println("Failed to enqueue released jobs:", err)
+	}
+
+	switch status {
+	case "":
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	case "CANCELLED":
+		w.Header().Set("Content-Type", "application/json")
+	case "RUNNING":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "Job is already "+status, http.StatusConflict)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":               jobId.String(),
+		"status":           status,
+		"cancel_requested": true,
+	})
+}
+
+// jobIDFromPath extracts the job id from /jobs/{id}{suffix}.
+func jobIDFromPath(path string, suffix string) (uuid.UUID, error) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(path, "/jobs/"), suffix)
+	return uuid.Parse(idStr)
+}
+
+// enqueueEntries wakes up jobs that became PENDING as a side effect, such as
+// retries, dependents unblocked by a finished job or group callbacks.
+// Entries due in the future are scheduled instead.
+func (h *Handler) enqueueEntries(ctx context.Context, entries []store.QueueEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	wakeups := make([]queue.Wakeup, len(entries))
+	for i, e := range entries {
+		wakeups[i] = queue.Wakeup{JobID: e.ID.String(), Queue: e.Queue, Priority: e.Priority, RunAt: e.RunAt}
+	}
+	return h.queue.EnqueueBatch(ctx, wakeups, time.Now())
+}
+
 func (h *Handler) AssignNextJob(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		WorkerId string `json:"worker_id"`
@@ -235,11 +602,12 @@ func (h *Handler) AssignNextJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := map[string]any{
-		"job_id":      job.ID.String(),
-		"type":        job.Type,
-		"payload":     job.Payload,
-		"retry_count": job.RetryCount,
-		"max_retries": job.MaxRetries,
+		"job_id":          job.ID.String(),
+		"type":            job.Type,
+		"payload":         job.Payload,
+		"retry_count":     job.RetryCount,
+		"max_retries":     job.MaxRetries,
+		"timeout_seconds": job.TimeoutSeconds,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -247,13 +615,23 @@ func (h *Handler) AssignNextJob(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// ReportJobResult records the outcome of a job. Reports from a worker that no
+// longer runs the job are rejected with 409. A successful job may carry a
+// JSON result. For failures the worker may set permanent, to skip the
+// remaining retries, or retry_after_seconds, to delay the next attempt by
+// that much instead of the job's backoff.
 func (h *Handler) ReportJobResult(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		JobId  string `json:"job_id"`
-		Status string `json:"status"`
-		Error  string `json:"error"`
+		JobId             string          `json:"job_id"`
+		WorkerId          string          `json:"worker_id"`
+		Status            string          `json:"status"`
+		Error             string          `json:"error"`
+		Result            json.RawMessage `json:"result"`
+		Permanent         bool            `json:"permanent"`
+		RetryAfterSeconds int             `json:"retry_after_seconds"`
 	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, maxReportBytes)
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -266,19 +644,53 @@ func (h *Handler) ReportJobResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Status != "SUCCESS" && req.Status != "FAILED" {
+	workerid, err := uuid.Parse(req.WorkerId)
+	if err != nil {
+		http.Error(w, "Invalid worker ID", http.StatusBadRequest)
+		return
+	}
+
+	if req.Status != "SUCCESS" && req.Status != "FAILED" && req.Status != "TIMEOUT" && req.Status != "CANCELLED" {
 		http.Error(w, "invalid status", http.StatusBadRequest)
 		return
 	}
 
-	if req.Status == "FAILED" {
-		err, retried := h.store.HandleJobFailures(r.Context(), jobid, req.Error)
+	// a timeout is a failed attempt like any other, but keeps a recognisable
+	// prefix so it can be told apart from handler errors
+	if req.Status == "TIMEOUT" {
+		req.Error = "TIMEOUT: " + req.Error
+	}
+
+	if req.Status == "FAILED" || req.Status == "TIMEOUT" {
+		if req.RetryAfterSeconds < 0 {
+			http.Error(w, "retry_after_seconds must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		// capped like any backoff delay, since a worker can't do much about
+		// a rejected report
+		retryAfter := backoff.MaxDelay
+		if req.RetryAfterSeconds < int(backoff.MaxDelay/time.Second) {
+			retryAfter = time.Duration(req.RetryAfterSeconds) * time.Second
+		}
+
+		entries, err := h.store.HandleJobFailures(r.Context(), jobid, store.Failure{
+			WorkerID:   workerid,
+			Error:      req.Error,
+			Permanent:  req.Permanent,
+			RetryAfter: retryAfter,
+		})
+		if errors.Is(err, store.ErrStaleReport) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		if err != nil {
 			http.Error(w, "Failed to handle job failure", http.StatusInternalServerError)
 			return
 		}
-		if retried {
-			h.queue.Enqueue(r.Context(), req.JobId)
+		// retries wait out their backoff in the scheduled set
+		if err := h.enqueueEntries(r.Context(), entries); err != nil {
+			log.Println("Failed to enqueue job", req.JobId, "for retry:", err)
 		}
 		w.WriteHeader(http.StatusOK)
 		return
@@ -287,11 +699,47 @@ func (h *Handler) ReportJobResult(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	err = h.store.ReportJobResult(ctx, jobid, req.Status, req.Error)
+	var result store.JobResult
+	if req.Status == "SUCCESS" {
+		// a stale attempt must not overwrite the result of the current one
+		running, err := h.store.JobRunsOn(ctx, jobid, workerid)
+		if err != nil {
+			http.Error(w, "Failed to report job result", http.StatusInternalServerError)
+			return
+		}
+		if !running {
+			http.Error(w, store.ErrStaleReport.Error(), http.StatusConflict)
+			return
+		}
+
+		result, err = h.storeResult(ctx, jobid, req.Result)
+		if errors.Is(err, errResultTooLarge) {
+			// the work itself succeeded, so the job still counts as done;
+			// only its output is dropped, with a note in the error
+			req.Error = err.Error() + ", result dropped"
+			err = nil
+		}
+		if err != nil {
+			http.Error(w, "Failed to store job result", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	unblocked, err := h.store.ReportJobResult(ctx, jobid, workerid, req.Status, req.Error, result)
+	if errors.Is(err, store.ErrStaleReport) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to report job result", http.StatusInternalServerError)
 		return
 	}
 
+	// dependents and group callbacks are PENDING now; if waking them fails
+	// the rewaker enqueues them shortly after
+	if err := h.enqueueEntries(ctx, unblocked); err != nil {
+		log.Println("Failed to enqueue unblocked jobs:", err)
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
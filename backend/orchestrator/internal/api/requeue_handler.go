@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+const (
+	defaultRequeueLimit = 100
+	maxRequeueLimit     = 1000
+)
+
+// requeueRequest is the body of POST /jobs/{id}/retry. Reason is required
+// and stored with the requeue. ResetRetries gives the job its full retry
+// budget again and ExtraRetries raises max_retries; with neither the job
+// keeps its retry count.
+type requeueRequest struct {
+	Reason       string `json:"reason"`
+	ResetRetries bool   `json:"reset_retries"`
+	ExtraRetries int    `json:"extra_retries"`
+}
+
+func (req requeueRequest) options() (store.RequeueOptions, error) {
+	if req.Reason == "" {
+		return store.RequeueOptions{}, errors.New("reason is required")
+	}
+	if req.ExtraRetries < 0 {
+		return store.RequeueOptions{}, errors.New("extra_retries must not be negative")
+	}
+	return store.RequeueOptions{
+		Reason:       req.Reason,
+		ResetRetries: req.ResetRetries,
+		ExtraRetries: req.ExtraRetries,
+	}, nil
+}
+
+// bulkRequeueRequest is the body of POST /jobs/retry and
+// POST /dead-letters/requeue, which requeue DEAD jobs matching Type,
+// ErrorContains (a case-insensitive substring) and ErrorSignature (a group
+// of the dead-letter view).
+type bulkRequeueRequest struct {
+	requeueRequest
+	Type           string `json:"type"`
+	ErrorContains  string `json:"error_contains"`
+	ErrorSignature string `json:"error_signature"`
+	Limit          int    `json:"limit"`
+}
+
+// RequeueJob puts a DEAD or CANCELLED job back onto its queue.
+func (h *Handler) RequeueJob(w http.ResponseWriter, r *http.Request) {
+	jobId, err := jobIDFromPath(r.URL.Path, "/retry")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	var req requeueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	opts, err := req.options()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	entry, status, err := h.store.RequeueJob(ctx, jobId, opts)
+	if errors.Is(err, store.ErrFailedDependency) {
+		http.Error(w, err.Error()+", requeue it first", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to requeue job", http.StatusInternalServerError)
+		return
+	}
+	if status == "" {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Job is "+status+", only DEAD or CANCELLED jobs can be retried", http.StatusConflict)
+		return
+	}
+
+	// a BLOCKED job is enqueued once its dependencies succeed
+	if entry.Status == "PENDING" {
+		if err := h.queue.Enqueue(ctx, entry.Queue, entry.ID.String(), entry.Priority); err != nil {
+			http.Error(w, "Failed to enqueue job", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"id":              entry.ID.String(),
+		"status":          entry.Status,
+		"previous_status": status,
+	})
+}
+
+// RequeueDeadJobs puts DEAD jobs matching a type and/or error filter back
+// onto their queues, up to a limit per request.
+func (h *Handler) RequeueDeadJobs(w http.ResponseWriter, r *http.Request) {
+	var req bulkRequeueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	opts, err := req.options()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = defaultRequeueLimit
+	}
+	if req.Limit > maxRequeueLimit {
+		http.Error(w, fmt.Sprintf("limit must not exceed %d", maxRequeueLimit), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	entries, err := h.store.RequeueDeadJobs(ctx, store.RequeueFilter{
+		Type:           req.Type,
+		ErrorContains:  req.ErrorContains,
+		ErrorSignature: req.ErrorSignature,
+	}, opts, req.Limit)
+	if err != nil {
+		http.Error(w, "Failed to requeue jobs", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	ids := make([]string, len(entries))
+	var wakeups []queue.Wakeup
+	for i, e := range entries {
+		ids[i] = e.ID.String()
+		if e.Status == "PENDING" {
+			wakeups = append(wakeups, queue.Wakeup{JobID: ids[i], Queue: e.Queue, Priority: e.Priority, RunAt: now})
+		}
+	}
+
+	// the jobs are already PENDING; if the wake-ups are lost the rewaker
+	// pushes them again
+	if err := h.queue.EnqueueBatch(ctx, wakeups, now); err != nil {
+		log.Println("Failed to enqueue requeued jobs:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"requeued": len(ids),
+		"blocked":  len(ids) - len(wakeups),
+		"ids":      ids,
+	})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/blob"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// maxReportBytes bounds the body of a /jobs/report request, result included.
+const maxReportBytes = 32 << 20
+
+var errResultTooLarge = errors.New("result too large")
+
+// storeResult decides where a job's result is kept: in the jobs table when
+// it fits within resultMaxBytes, otherwise in the blob store.
+func (h *Handler) storeResult(ctx context.Context, jobID uuid.UUID, data json.RawMessage) (store.JobResult, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return store.JobResult{}, nil
+	}
+	if len(data) <= h.resultMaxBytes {
+		return store.JobResult{Data: data}, nil
+	}
+	if h.blobs == nil {
+		return store.JobResult{}, fmt.Errorf("%w: %d bytes, the limit is %d", errResultTooLarge, len(data), h.resultMaxBytes)
+	}
+
+	ref, err := h.blobs.Put(ctx, jobID.String()+".json", data)
+	if err != nil {
+		return store.JobResult{}, err
+	}
+	return store.JobResult{Ref: ref}, nil
+}
+
+// GetJobResult returns the raw result of a job, reading it from the blob
+// store when it was too large for the jobs table.
+func (h *Handler) GetJobResult(w http.ResponseWriter, r *http.Request) {
+	jobId, err := jobIDFromPath(r.URL.Path, "/result")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	result, found, err := h.store.GetJobResult(ctx, jobId)
+	if err != nil {
+		http.Error(w, "Failed to fetch job result", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	data := []byte(result.Data)
+	if result.Ref != "" {
+		if h.blobs == nil {
+			http.Error(w, "Blob store is not configured", http.StatusInternalServerError)
+			return
+		}
+		data, err = h.blobs.Get(ctx, result.Ref)
+		if errors.Is(err, blob.ErrNotFound) {
+			http.Error(w, "Job result not found in blob store", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to fetch job result", http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(data) == 0 {
+		http.Error(w, "Job has no result", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -1,6 +1,9 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
@@ -14,7 +17,71 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		}
 	})
 
+	mux.HandleFunc("/jobs/retry", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.RequeueDeadJobs(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/dead-letters", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			h.ListDeadLetters(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/dead-letters/requeue", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.RequeueDeadJobs(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/dead-letters/purge", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.PurgeDeadLetters(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/jobs/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.CreateJobBatch(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
 	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/retry") {
+			h.RequeueJob(w, r)
+			return
+		}
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cancel") {
+			h.CancelJob(w, r)
+			return
+		}
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/result") {
+			h.GetJobResult(w, r)
+			return
+		}
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/attempts") {
+			h.ListJobAttempts(w, r)
+			return
+		}
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/graph") {
+			h.GetJobGraph(w, r)
+			return
+		}
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/webhooks") {
+			h.ListWebhookDeliveries(w, r)
+			return
+		}
 		if r.Method == http.MethodGet {
 			h.GetJobDetail(w, r)
 			return
@@ -22,6 +89,38 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	})
 
+	mux.HandleFunc("/workflows", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.CreateWorkflow(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/workflows/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			h.GetWorkflow(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/groups", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.CreateGroup(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/groups/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			h.GetGroup(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
 	mux.HandleFunc("/workers/register", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
 			h.RegisterWorker(w, r)
@@ -38,6 +137,22 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	})
 
+	mux.HandleFunc("/workers/drain", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.DrainWorker(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc("/workers/deregister", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.DeregisterWorker(w, r)
+			return
+		}
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	})
+
 	mux.HandleFunc("/workers", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			h.ListWorkers(w, r)
@@ -62,4 +177,44 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	})
 
+	mux.HandleFunc("/schedules", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.CreateSchedule(w, r)
+		case http.MethodGet:
+			h.ListSchedules(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/schedules/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetSchedule(w, r)
+		case http.MethodPut:
+			h.UpdateSchedule(w, r)
+		case http.MethodDelete:
+			h.DeleteSchedule(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/job-types/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/backoff") {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			h.GetTypeBackoff(w, r)
+		case http.MethodPut:
+			h.SetTypeBackoff(w, r)
+		case http.MethodDelete:
+			h.DeleteTypeBackoff(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
 }
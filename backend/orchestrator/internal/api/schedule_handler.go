@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/cron"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+type scheduleRequest struct {
+	Name            string          `json:"name"`
+	Cron            string          `json:"cron"`
+	Type            string          `json:"type"`
+	PayloadTemplate json.RawMessage `json:"payload_template"`
+	MaxRetries      int             `json:"max_retries"`
+	TimeoutSeconds  int             `json:"timeout_seconds"`
+	Enabled         *bool           `json:"enabled"`
+}
+
+type ScheduleDTO struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Cron            string          `json:"cron"`
+	Type            string          `json:"type"`
+	PayloadTemplate json.RawMessage `json:"payload_template"`
+	MaxRetries      int             `json:"max_retries"`
+	TimeoutSeconds  int             `json:"timeout_seconds"`
+	Enabled         bool            `json:"enabled"`
+	NextRunAt       time.Time       `json:"next_run_at"`
+	LastRunAt       *time.Time      `json:"last_run_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// toSchedule validates the request and builds the schedule it describes,
+// with next_run_at set to the first tick after now.
+func (req *scheduleRequest) toSchedule(id uuid.UUID) (*store.Schedule, error) {
+	if req.Type == "" {
+		return nil, errors.New("type is required")
+	}
+
+	expr, err := cron.Parse(req.Cron)
+	if err != nil {
+		return nil, err
+	}
+	next := expr.Next(time.Now())
+	if next.IsZero() {
+		return nil, errors.New("cron expression never fires")
+	}
+
+	payload := req.PayloadTemplate
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+	if !json.Valid(payload) {
+		return nil, errors.New("payload_template must be valid JSON")
+	}
+
+	if req.TimeoutSeconds <= 0 {
+		req.TimeoutSeconds = defaultTimeoutSeconds
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	name := req.Name
+	if name == "" {
+		name = req.Type
+	}
+
+	return &store.Schedule{
+		ID:              id,
+		Name:            name,
+		CronExpr:        req.Cron,
+		JobType:         req.Type,
+		PayloadTemplate: payload,
+		MaxRetries:      req.MaxRetries,
+		TimeoutSeconds:  req.TimeoutSeconds,
+		Enabled:         enabled,
+		NextRunAt:       next,
+	}, nil
+}
+
+func toScheduleDTO(sch *store.Schedule) ScheduleDTO {
+	return ScheduleDTO{
+		ID:              sch.ID.String(),
+		Name:            sch.Name,
+		Cron:            sch.CronExpr,
+		Type:            sch.JobType,
+		PayloadTemplate: sch.PayloadTemplate,
+		MaxRetries:      sch.MaxRetries,
+		TimeoutSeconds:  sch.TimeoutSeconds,
+		Enabled:         sch.Enabled,
+		NextRunAt:       sch.NextRunAt,
+		LastRunAt:       sch.LastRunAt,
+		CreatedAt:       sch.CreatedAt,
+		UpdatedAt:       sch.UpdatedAt,
+	}
+}
+
+func (h *Handler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sch, err := req.toSchedule(uuid.New())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if err := h.store.CreateSchedule(ctx, sch); err != nil {
+		http.Error(w, "Failed to create schedule", http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.store.GetSchedule(ctx, sch.ID)
+	if err != nil || created == nil {
+		http.Error(w, "Failed to fetch schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toScheduleDTO(created))
+}
+
+func (h *Handler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	schedules, err := h.store.ListSchedules(ctx)
+	if err != nil {
+		http.Error(w, "Failed to list schedules", http.StatusInternalServerError)
+		return
+	}
+
+	dtos := make([]ScheduleDTO, 0, len(schedules))
+	for _, sch := range schedules {
+		dtos = append(dtos, toScheduleDTO(sch))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"schedules": dtos,
+	})
+}
+
+func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := scheduleIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	sch, err := h.store.GetSchedule(ctx, id)
+	if err != nil {
+		http.Error(w, "Failed to fetch schedule", http.StatusInternalServerError)
+		return
+	}
+	if sch == nil {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toScheduleDTO(sch))
+}
+
+func (h *Handler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := scheduleIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sch, err := req.toSchedule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	found, err := h.store.UpdateSchedule(ctx, sch)
+	if err != nil {
+		http.Error(w, "Failed to update schedule", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	updated, err := h.store.GetSchedule(ctx, id)
+	if err != nil || updated == nil {
+		http.Error(w, "Failed to fetch schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toScheduleDTO(updated))
+}
+
+func (h *Handler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := scheduleIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	found, err := h.store.DeleteSchedule(ctx, id)
+	if err != nil {
+		http.Error(w, "Failed to delete schedule", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func scheduleIDFromPath(path string) (uuid.UUID, error) {
+	return uuid.Parse(strings.TrimPrefix(path, "/schedules/"))
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type webhookDeliveryDTO struct {
+	ID             string     `json:"id"`
+	URL            string     `json:"url"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ListWebhookDeliveries returns the delivery log of a job's completion
+// webhooks.
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	jobId, err := jobIDFromPath(r.URL.Path, "/webhooks")
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	deliveries, err := h.store.ListWebhookDeliveries(ctx, jobId)
+	if err != nil {
+		http.Error(w, "Failed to fetch webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	dtos := make([]webhookDeliveryDTO, len(deliveries))
+	for i, d := range deliveries {
+		dtos[i] = webhookDeliveryDTO{
+			ID:             d.ID.String(),
+			URL:            d.URL,
+			Event:          d.Event,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			DeliveredAt:    d.DeliveredAt,
+			CreatedAt:      d.CreatedAt,
+		}
+		// only a pending delivery has a next attempt
+		if d.Status == "PENDING" {
+			next := d.NextAttemptAt
+			dtos[i].NextAttemptAt = &next
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"job_id":     jobId.String(),
+		"deliveries": dtos,
+	})
+}
@@ -7,13 +7,23 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
 )
 
+// Queues lists the queues the worker takes jobs from; a worker that names
+// none serves the default queue. JobTypes lists the job types its executor
+// can run; a worker that names none is handed jobs of any type. Slots is the
+// number of jobs it runs concurrently, 1 when omitted.
 type registerWorkerRequest struct {
-	Hostname string `json:"hostname"`
+	Hostname string   `json:"hostname"`
+	Queues   []string `json:"queues"`
+	JobTypes []string `json:"job_types"`
+	Slots    int      `json:"slots"`
 }
 
-type heartbeatRequest struct {
+// workerRequest identifies the calling worker on heartbeat, drain and
+// deregister.
+type workerRequest struct {
 	ID string `json:"id"`
 }
 
@@ -22,6 +32,11 @@ type WorkerDTO struct {
 	Hostname      string    `json:"hostname"`
 	Status        string    `json:"status"`
 	LastHeartbeat time.Time `json:"last_heartbeat"`
+	Queues        []string  `json:"queues"`
+	JobTypes      []string  `json:"job_types"`
+	Slots         int       `json:"slots"`
+	RunningJobs   int       `json:"running_jobs"`
+	Utilization   float64   `json:"utilization"`
 }
 
 func (h *Handler) RegisterWorker(w http.ResponseWriter, r *http.Request) {
@@ -32,10 +47,29 @@ func (h *Handler) RegisterWorker(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Slots <= 0 {
+		req.Slots = 1
+	}
+
+	if len(req.Queues) == 0 {
+		req.Queues = []string{store.DefaultQueue}
+	}
+	for _, q := range req.Queues {
+		if !queueNamePattern.MatchString(q) {
+			http.Error(w, "invalid queue name", http.StatusBadRequest)
+			return
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	worker, err := h.store.CreateWorker(ctx, req.Hostname)
+	worker, err := h.store.CreateWorker(ctx, store.WorkerRegistration{
+		Hostname: req.Hostname,
+		Queues:   req.Queues,
+		JobTypes: req.JobTypes,
+		Slots:    req.Slots,
+	})
 	if err != nil {
 		http.Error(w, "Failed to create worker", http.StatusInternalServerError)
 		return
@@ -49,7 +83,7 @@ func (h *Handler) RegisterWorker(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
-	var req heartbeatRequest
+	var req workerRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
@@ -66,14 +100,76 @@ func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	err = h.store.UpdateHeartbeat(ctx, workerID)
+	status, err := h.store.UpdateHeartbeat(ctx, workerID)
+	if err != nil {
+		http.Error(w, "Failed to update heartbeat", http.StatusInternalServerError)
+		return
+	}
+	if status == "" {
+		http.Error(w, "Worker not found", http.StatusNotFound)
+		return
+	}
+
+	// running jobs that were cancelled since the last heartbeat; the worker
+	// stops them and reports them as CANCELLED
+	cancelled, err := h.store.CancelledRunningJobs(ctx, workerID)
 	if err != nil {
 		http.Error(w, "Failed to update heartbeat", http.StatusInternalServerError)
 		return
 	}
 
+	cancelJobs := make([]string, len(cancelled))
+	for i, id := range cancelled {
+		cancelJobs[i] = id.String()
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":      status,
+		"cancel_jobs": cancelJobs,
+	})
+}
+
+// DrainWorker stops assigning new jobs to a worker while it finishes the
+// ones it is running.
+func (h *Handler) DrainWorker(w http.ResponseWriter, r *http.Request) {
+	h.setWorkerStatus(w, r, "DRAINING")
+}
+
+// DeregisterWorker takes a worker out of rotation for good. Any job it still
+// holds is reclaimed by the worker monitor like that of a crashed worker.
+func (h *Handler) DeregisterWorker(w http.ResponseWriter, r *http.Request) {
+	h.setWorkerStatus(w, r, "OFFLINE")
+}
+
+func (h *Handler) setWorkerStatus(w http.ResponseWriter, r *http.Request, status string) {
+	var req workerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	workerID, err := uuid.Parse(req.ID)
+	if err != nil {
+		http.Error(w, "Invalid worker ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	found, err := h.store.SetWorkerStatus(ctx, workerID, status)
+	if err != nil {
+		http.Error(w, "Failed to update worker status", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Worker not found", http.StatusNotFound)
+		return
+	}
+
 	json.NewEncoder(w).Encode(map[string]string{
-		"status": "ONLINE",
+		"status": status,
 	})
 }
 
@@ -89,11 +185,20 @@ func (h *Handler) ListWorkers(w http.ResponseWriter, r *http.Request) {
 
 	var workerDTOs []WorkerDTO
 	for _, w := range workers {
+		var utilization float64
+		if w.Slots > 0 {
+			utilization = float64(w.RunningJobs) / float64(w.Slots)
+		}
 		workerDTOs = append(workerDTOs, WorkerDTO{
 			ID:            w.ID.String(),
 			Hostname:      w.Hostname,
 			Status:        w.Status,
 			LastHeartbeat: w.LastHeartbeat,
+			Queues:        w.Queues,
+			JobTypes:      w.JobTypes,
+			Slots:         w.Slots,
+			RunningJobs:   w.RunningJobs,
+			Utilization:   utilization,
 		})
 	}
 
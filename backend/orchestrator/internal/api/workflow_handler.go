@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// maxWorkflowSteps caps the number of steps of one workflow.
+const maxWorkflowSteps = 1000
+
+// workflowRequest is the body of POST /workflows. Each step is a create job
+// request with a name, unique within the workflow; its depends_on lists the
+// names of other steps rather than job ids.
+type workflowRequest struct {
+	Name  string                `json:"name"`
+	Steps []workflowStepRequest `json:"steps"`
+}
+
+type workflowStepRequest struct {
+	Name string `json:"name"`
+	createJobRequest
+}
+
+type workflowStepDTO struct {
+	Name      string    `json:"name"`
+	JobID     string    `json:"job_id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	Error     *string   `json:"error,omitempty"`
+	DependsOn []string  `json:"depends_on"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// newWorkflow validates req and turns it into a workflow whose steps are in
+// topological order. The returned error is meant for the client.
+func newWorkflow(req workflowRequest) (*store.WorkflowCreate, error) {
+	if req.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(req.Steps) == 0 {
+		return nil, errors.New("a workflow needs at least one step")
+	}
+	if len(req.Steps) > maxWorkflowSteps {
+		return nil, fmt.Errorf("a workflow must not have more than %d steps", maxWorkflowSteps)
+	}
+
+	index := make(map[string]int, len(req.Steps))
+	for i, step := range req.Steps {
+		if step.Name == "" {
+			return nil, fmt.Errorf("step %d has no name", i)
+		}
+		if _, dup := index[step.Name]; dup {
+			return nil, fmt.Errorf("duplicate step name %q", step.Name)
+		}
+		index[step.Name] = i
+	}
+
+	// Kahn's algorithm; steps that become ready together keep their
+	// submission order
+	pending := make([]int, len(req.Steps))
+	dependents := make([][]int, len(req.Steps))
+	for i, step := range req.Steps {
+		if step.IdempotencyKey != "" {
+			return nil, fmt.Errorf("step %q: idempotency keys are not supported in workflows", step.Name)
+		}
+		for _, dep := range step.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("step %q depends on unknown step %q", step.Name, dep)
+			}
+			if j == i {
+				return nil, fmt.Errorf("step %q depends on itself", step.Name)
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	var order []int
+	for i := range req.Steps {
+		if pending[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for k := 0; k < len(order); k++ {
+		for _, i := range dependents[order[k]] {
+			pending[i]--
+			if pending[i] == 0 {
+				order = append(order, i)
+			}
+		}
+	}
+	if len(order) < len(req.Steps) {
+		var cyclic []string
+		for i, n := range pending {
+			if n > 0 {
+				cyclic = append(cyclic, req.Steps[i].Name)
+			}
+		}
+		return nil, fmt.Errorf("steps %s form a cycle", strings.Join(cyclic, ", "))
+	}
+
+	wf := &store.WorkflowCreate{ID: uuid.New(), Name: req.Name}
+	ids := make([]uuid.UUID, len(req.Steps))
+	for _, i := range order {
+		step := req.Steps[i]
+		names := step.DependsOn
+		step.DependsOn = nil
+
+		job, err := newJob(step.createJobRequest)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		for _, name := range names {
+			job.DependsOn = append(job.DependsOn, ids[index[name]])
+		}
+		ids[i] = job.ID
+		wf.Steps = append(wf.Steps, store.WorkflowStep{Name: step.Name, Index: i, Job: job})
+	}
+	return wf, nil
+}
+
+// CreateWorkflow creates all the jobs of a workflow at once and enqueues the
+// steps that don't depend on any other.
+func (h *Handler) CreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	var req workflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	wf, err := newWorkflow(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.CreateWorkflow(ctx, wf); err != nil {
+		http.Error(w, "Failed to create workflow", http.StatusInternalServerError)
+		return
+	}
+
+	dependsOn := make(map[string][]string, len(req.Steps))
+	for _, step := range req.Steps {
+		dependsOn[step.Name] = append([]string{}, step.DependsOn...)
+	}
+
+	steps := make([]workflowStepDTO, len(wf.Steps))
+	var wakeups []queue.Wakeup
+	for _, step := range wf.Steps {
+		job := step.Job
+		steps[step.Index] = workflowStepDTO{
+			Name:      step.Name,
+			JobID:     job.ID.String(),
+			Type:      job.Type,
+			Status:    job.Status,
+			DependsOn: dependsOn[step.Name],
+		}
+		if job.Status == "PENDING" {
+			wakeups = append(wakeups, queue.Wakeup{
+				JobID:    job.ID.String(),
+				Queue:    job.Queue,
+				Priority: job.Priority,
+				RunAt:    job.RunAt,
+			})
+		}
+	}
+
+	// the jobs are committed at this point; the rewaker covers a failed
+	// enqueue
+	if err := h.queue.EnqueueBatch(ctx, wakeups, now); err != nil {
+		log.Println("Failed to enqueue workflow steps:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":     wf.ID.String(),
+		"name":   wf.Name,
+		"status": "RUNNING",
+		"steps":  steps,
+	})
+}
+
+// GetWorkflow returns a workflow's status and the state of each step.
+func (h *Handler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/workflows/"))
+	if err != nil {
+		http.Error(w, "Invalid workflow ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	wf, err := h.store.GetWorkflow(ctx, id)
+	if err != nil {
+		http.Error(w, "Failed to fetch workflow", http.StatusInternalServerError)
+		return
+	}
+	if wf == nil {
+		http.Error(w, "Workflow not found", http.StatusNotFound)
+		return
+	}
+
+	steps := make([]workflowStepDTO, len(wf.Steps))
+	for i, step := range wf.Steps {
+		steps[i] = workflowStepDTO{
+			Name:      step.Name,
+			JobID:     step.JobID.String(),
+			Type:      step.Type,
+			Status:    step.Status,
+			Error:     step.Error,
+			DependsOn: step.DependsOn,
+			UpdatedAt: step.UpdatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":         wf.ID.String(),
+		"name":       wf.Name,
+		"status":     wf.Status,
+		"created_at": wf.CreatedAt,
+		"updated_at": wf.UpdatedAt,
+		"steps":      steps,
+	})
+}
@@ -0,0 +1,90 @@
+// Package backoff computes how long a failed job waits before its next
+// attempt. A Policy is stored as JSON, either on a job or as the default for
+// a job type.
+package backoff
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	Fixed       = "fixed"
+	Linear      = "linear"
+	Exponential = "exponential"
+)
+
+// maxSeconds caps every delay, whatever the policy says.
+const maxSeconds = 7 * 24 * 60 * 60
+
+// MaxDelay is the longest a failed job ever waits before its next attempt.
+const MaxDelay = maxSeconds * time.Second
+
+// Policy describes the delay before retry n (1 for the first retry):
+//
+//	fixed:       base
+//	linear:      base * n
+//	exponential: base * 2^(n-1)
+//
+// capped at MaxSeconds when it is set. With Jitter the delay is drawn
+// uniformly from its upper half, so retries of jobs that failed together
+// spread out.
+type Policy struct {
+	Strategy    string `json:"strategy"`
+	BaseSeconds int    `json:"base_seconds"`
+	MaxSeconds  int    `json:"max_seconds,omitempty"`
+	Jitter      bool   `json:"jitter,omitempty"`
+}
+
+// Default applies to jobs without a policy of their own or of their type.
+var Default = Policy{
+	Strategy:    Exponential,
+	BaseSeconds: 2,
+	MaxSeconds:  300,
+	Jitter:      true,
+}
+
+// Validate reports whether p is a usable policy.
+func (p Policy) Validate() error {
+	switch p.Strategy {
+	case Fixed, Linear, Exponential:
+	default:
+		return fmt.Errorf("unknown backoff strategy %q", p.Strategy)
+	}
+	if p.BaseSeconds < 0 || p.BaseSeconds > maxSeconds {
+		return fmt.Errorf("base_seconds must be between 0 and %d", maxSeconds)
+	}
+	if p.MaxSeconds < 0 || p.MaxSeconds > maxSeconds {
+		return fmt.Errorf("max_seconds must be between 0 and %d", maxSeconds)
+	}
+	if p.MaxSeconds > 0 && p.MaxSeconds < p.BaseSeconds {
+		return errors.New("max_seconds must not be lower than base_seconds")
+	}
+	return nil
+}
+
+// Delay returns the wait before retry n.
+func (p Policy) Delay(n int) time.Duration {
+	n = max(n, 1)
+	limit := maxSeconds
+	if p.MaxSeconds > 0 {
+		limit = p.MaxSeconds
+	}
+
+	secs := p.BaseSeconds
+	switch p.Strategy {
+	case Linear:
+		secs = p.BaseSeconds * min(n, limit)
+	case Exponential:
+		// past 2^20 the delay is over any limit anyway
+		secs = p.BaseSeconds << min(n-1, 20)
+	}
+	d := time.Duration(min(secs, limit)) * time.Second
+
+	if p.Jitter && d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+	return d
+}
@@ -0,0 +1,79 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		ok     bool
+	}{
+		{"fixed", Policy{Strategy: Fixed, BaseSeconds: 5}, true},
+		{"linear", Policy{Strategy: Linear, BaseSeconds: 5, MaxSeconds: 60}, true},
+		{"exponential", Policy{Strategy: Exponential, BaseSeconds: 2, MaxSeconds: 300, Jitter: true}, true},
+		{"zero base", Policy{Strategy: Fixed}, true},
+		{"max equals base", Policy{Strategy: Fixed, BaseSeconds: 10, MaxSeconds: 10}, true},
+		{"base at limit", Policy{Strategy: Fixed, BaseSeconds: maxSeconds}, true},
+		{"unknown strategy", Policy{Strategy: "random", BaseSeconds: 5}, false},
+		{"empty strategy", Policy{BaseSeconds: 5}, false},
+		{"negative base", Policy{Strategy: Fixed, BaseSeconds: -1}, false},
+		{"base over limit", Policy{Strategy: Fixed, BaseSeconds: maxSeconds + 1}, false},
+		{"negative max", Policy{Strategy: Linear, BaseSeconds: 5, MaxSeconds: -1}, false},
+		{"max over limit", Policy{Strategy: Linear, BaseSeconds: 5, MaxSeconds: maxSeconds + 1}, false},
+		{"max below base", Policy{Strategy: Linear, BaseSeconds: 10, MaxSeconds: 5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		n      int
+		want   time.Duration
+	}{
+		{"fixed first", Policy{Strategy: Fixed, BaseSeconds: 5}, 1, 5 * time.Second},
+		{"fixed later", Policy{Strategy: Fixed, BaseSeconds: 5}, 7, 5 * time.Second},
+		{"linear", Policy{Strategy: Linear, BaseSeconds: 5}, 3, 15 * time.Second},
+		{"linear capped", Policy{Strategy: Linear, BaseSeconds: 5, MaxSeconds: 12}, 3, 12 * time.Second},
+		{"exponential first", Policy{Strategy: Exponential, BaseSeconds: 2}, 1, 2 * time.Second},
+		{"exponential", Policy{Strategy: Exponential, BaseSeconds: 2}, 4, 16 * time.Second},
+		{"exponential capped", Policy{Strategy: Exponential, BaseSeconds: 2, MaxSeconds: 300}, 10, 300 * time.Second},
+		{"exponential far out", Policy{Strategy: Exponential, BaseSeconds: 1}, 1000, MaxDelay},
+		{"linear far out", Policy{Strategy: Linear, BaseSeconds: maxSeconds}, 1 << 40, MaxDelay},
+		{"retry zero counts as first", Policy{Strategy: Exponential, BaseSeconds: 3}, 0, 3 * time.Second},
+		{"zero base", Policy{Strategy: Exponential}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.n); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	p := Policy{Strategy: Exponential, BaseSeconds: 10, MaxSeconds: 60, Jitter: true}
+	for n := 1; n <= 5; n++ {
+		full := Policy{Strategy: p.Strategy, BaseSeconds: p.BaseSeconds, MaxSeconds: p.MaxSeconds}.Delay(n)
+		for range 100 {
+			got := p.Delay(n)
+			if got < full/2 || got > full {
+				t.Fatalf("Delay(%d) = %v, want within [%v, %v]", n, got, full/2, full)
+			}
+		}
+	}
+}
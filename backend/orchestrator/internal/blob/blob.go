@@ -0,0 +1,23 @@
+// Package blob stores job results that are too large to keep in Postgres.
+// Results are written once under a key and read back through the reference
+// returned by Put, which is what the jobs table records.
+package blob
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by Get for a reference with no stored blob.
+var ErrNotFound = errors.New("blob not found")
+
+// Store is an external blob store. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Put stores data under key, replacing any previous blob with that key,
+	// and returns the reference to read it back with.
+	Put(ctx context.Context, key string, data []byte) (string, error)
+
+	// Get returns the blob behind a reference returned by Put.
+	Get(ctx context.Context, ref string) ([]byte, error)
+}
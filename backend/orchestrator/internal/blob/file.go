@@ -0,0 +1,80 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// filePrefix marks references produced by FileStore.
+const filePrefix = "file:"
+
+// FileStore keeps blobs as files in a local directory, or a shared volume
+// when several orchestrators run side by side.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore rooted at dir, creating the directory if
+// needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) (string, error) {
+	name, err := fileName(key)
+	if err != nil {
+		return "", err
+	}
+
+	// write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return "", err
+	}
+
+	return filePrefix + name, nil
+}
+
+func (s *FileStore) Get(ctx context.Context, ref string) ([]byte, error) {
+	name, ok := strings.CutPrefix(ref, filePrefix)
+	if !ok {
+		return nil, fmt.Errorf("not a file blob reference: %q", ref)
+	}
+	if _, err := fileName(name); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// fileName checks that key can be used as a file name inside the store's
+// directory.
+func fileName(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return key, nil
+}
@@ -0,0 +1,103 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{"* * * * *", true},
+		{"*/15 * * * *", true},
+		{"0 9-17 * * 1-5", true},
+		{"0 0 1,15 * *", true},
+		{"5/10 * * * *", true},
+		{"0 0 * * 7", true},
+		{"0 0 1-31/2 * *", true},
+		{"  0 0 * * *  ", true},
+		{"@hourly", true},
+		{"@daily", true},
+		{"@weekly", true},
+		{"@monthly", true},
+		{"@yearly", true},
+		{"@annually", true},
+		{"@midnight", true},
+		{"", false},
+		{"* * * *", false},
+		{"* * * * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * 32 * *", false},
+		{"* * * 13 *", false},
+		{"* * * * 8", false},
+		{"5-1 * * * *", false},
+		{"*/0 * * * *", false},
+		{"*/x * * * *", false},
+		{"a * * * *", false},
+		{"1,,2 * * * *", false},
+		{"@every 5m", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err == nil) != tt.ok {
+				t.Errorf("Parse(%q) = %v, want ok %v", tt.expr, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-03-10 is a Tuesday
+	from := time.Date(2026, 3, 10, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", from, time.Date(2026, 3, 10, 10, 31, 0, 0, time.UTC)},
+		{"strictly after", "30 10 * * *", time.Date(2026, 3, 10, 10, 30, 0, 0, time.UTC), time.Date(2026, 3, 11, 10, 30, 0, 0, time.UTC)},
+		{"minute step", "*/15 * * * *", from, time.Date(2026, 3, 10, 10, 45, 0, 0, time.UTC)},
+		{"offset step", "5/20 * * * *", from, time.Date(2026, 3, 10, 10, 45, 0, 0, time.UTC)},
+		{"next hour", "0 * * * *", from, time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC)},
+		{"hourly", "@hourly", from, time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC)},
+		{"daily", "@daily", from, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"weekly", "@weekly", from, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"monthly", "@monthly", from, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", "@yearly", from, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"hour range", "0 9-17 * * *", time.Date(2026, 3, 10, 17, 30, 0, 0, time.UTC), time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC)},
+		{"weekdays", "0 9 * * 1-5", time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", from, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"day list", "0 0 1,15 * *", from, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"month wrap", "0 0 1 1 *", time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"day 31 skips short months", "0 0 31 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 *", from, time.Time{}},
+		// both day fields restricted: either one matches
+		{"dom or dow", "0 0 12 * 5", from, time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{"dow or dom", "0 0 20 * 3", from, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		// a stepped * is still unrestricted, so the other field must match
+		{"dom step and dow", "0 0 */2 * 1", from, time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)},
+		{"dow step and dom", "0 0 15 * */2", from, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"non-UTC input", "0 12 * * *", time.Date(2026, 3, 10, 13, 0, 0, 0, time.FixedZone("CET", 3600)), time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
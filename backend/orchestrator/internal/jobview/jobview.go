@@ -0,0 +1,107 @@
+// Package jobview renders a job and its attempts the way the API returns
+// them from GET /jobs/{id}. Webhook deliveries carry the same body, which the
+// store renders through Marshal when the job finishes.
+package jobview
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+type Detail struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	RetryCount     int             `json:"retry_count"`
+	MaxRetries     int             `json:"max_retries"`
+	TimeoutSeconds int             `json:"timeout_seconds"`
+	Queue          string          `json:"queue"`
+	Priority       int             `json:"priority"`
+	WorkerID       *string         `json:"worker_id"`
+	Error          *string         `json:"error"`
+	RunAt          time.Time       `json:"run_at"`
+	Backoff        json.RawMessage `json:"backoff"`
+	Result         json.RawMessage `json:"result"`
+	ResultRef      *string         `json:"result_ref"`
+	CallbackURL    *string         `json:"callback_url"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Attempts       []Attempt       `json:"attempts"`
+}
+
+type Attempt struct {
+	AttemptNumber  int        `json:"attempt_number"`
+	Status         string     `json:"status"`
+	Error          *string    `json:"error"`
+	WorkerID       *string    `json:"worker_id"`
+	WorkerHostname *string    `json:"worker_hostname"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	DurationMs     *int64     `json:"duration_ms"`
+}
+
+// NewDetail renders a job and its attempts.
+func NewDetail(job *store.JobDetail, attempts []store.JobAttempt) Detail {
+	var workerID *string
+	if job.WorkerID != nil {
+		wid := job.WorkerID.String()
+		workerID = &wid
+	}
+
+	return Detail{
+		ID:             job.ID.String(),
+		Type:           job.Type,
+		Payload:        job.Payload,
+		Status:         job.Status,
+		RetryCount:     job.RetryCount,
+		MaxRetries:     job.MaxRetries,
+		TimeoutSeconds: job.TimeoutSeconds,
+		Queue:          job.Queue,
+		Priority:       job.Priority,
+		WorkerID:       workerID,
+		Error:          job.Error,
+		RunAt:          job.RunAt,
+		Backoff:        job.Backoff,
+		Result:         job.Result,
+		ResultRef:      job.ResultRef,
+		CallbackURL:    job.CallbackURL,
+		CreatedAt:      job.CreatedAt,
+		UpdatedAt:      job.UpdatedAt,
+		Attempts:       NewAttempts(attempts),
+	}
+}
+
+// Marshal renders a job and its attempts as JSON. It is the store's
+// renderer for webhook bodies.
+func Marshal(job *store.JobDetail, attempts []store.JobAttempt) ([]byte, error) {
+	return json.Marshal(NewDetail(job, attempts))
+}
+
+// NewAttempts renders the attempts of a job, as listed by
+// GET /jobs/{id}/attempts.
+func NewAttempts(attempts []store.JobAttempt) []Attempt {
+	views := make([]Attempt, 0, len(attempts))
+	for _, a := range attempts {
+		v := Attempt{
+			AttemptNumber:  a.AttemptNumber,
+			Status:         a.Status,
+			Error:          a.Error,
+			WorkerHostname: a.WorkerHostname,
+			StartedAt:      a.StartedAt,
+			FinishedAt:     a.FinishedAt,
+		}
+		if a.WorkerID != nil {
+			wid := a.WorkerID.String()
+			v.WorkerID = &wid
+		}
+		if a.StartedAt != nil && a.FinishedAt != nil {
+			ms := a.FinishedAt.Sub(*a.StartedAt).Milliseconds()
+			v.DurationMs = &ms
+		}
+		views = append(views, v)
+	}
+	return views
+}
@@ -4,10 +4,68 @@ package queue
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// jobQueueKey is the list of the default queue; named queues live under
+	// namedQueuePrefix + name.
+	jobQueueKey      = "job_queue"
+	namedQueuePrefix = "queue:"
+	defaultQueue     = "default"
+
+	// scheduledKey is a sorted set of "<list key>|<job id>" members scored by
+	// the unix millisecond at which the job becomes due.
+	scheduledKey = "scheduled_jobs"
+)
+
+// Jobs are spread over three Redis lists by priority. Workers BRPOP the
+// lists in band order, so a backlog of low priority wake-ups never delays a
+// worker from picking up an urgent job.
+const (
+	highBandSuffix = ":high"
+	lowBandSuffix  = ":low"
+)
+
+// listKey maps a queue name and job priority to its Redis list: positive
+// priorities are high, negative ones low and zero is the normal band.
+func listKey(queueName string, priority int) string {
+	base := jobQueueKey
+	if queueName != "" && queueName != defaultQueue {
+		base = namedQueuePrefix + queueName
+	}
+
+	switch {
+	case priority > 0:
+		return base + highBandSuffix
+	case priority < 0:
+		return base + lowBandSuffix
+	default:
+		return base
+	}
+}
+
+// promoteScript atomically moves due members of the scheduled set onto their
+// lists, so a job is pushed exactly once even with several orchestrators
+// promoting at the same time. Bare job ids fall back to KEYS[2].
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, member in ipairs(due) do
+	redis.call('ZREM', KEYS[1], member)
+	local sep = string.find(member, '|', 1, true)
+	if sep then
+		redis.call('LPUSH', string.sub(member, 1, sep - 1), string.sub(member, sep + 1))
+	else
+		redis.call('LPUSH', KEYS[2], member)
+	end
+end
+return #due
+`)
+
 type Queue struct {
 	client *redis.Client
 }
@@ -22,6 +80,67 @@ func New(redisUrl string) *Queue {
 	}
 }
 
-func (q *Queue) Enqueue(ctx context.Context, jobId string) error {
-	return q.client.LPush(ctx, "job_queue", jobId).Err()
+func (q *Queue) Enqueue(ctx context.Context, queueName string, jobId string, priority int) error {
+	return q.client.LPush(ctx, listKey(queueName, priority), jobId).Err()
+}
+
+// Schedule holds a job back until runAt, after which PromoteDue pushes it
+// onto the list for its queue and priority.
+func (q *Queue) Schedule(ctx context.Context, queueName string, jobId string, priority int, runAt time.Time) error {
+	return q.client.ZAdd(ctx, scheduledKey, redis.Z{
+		Score:  float64(runAt.UnixMilli()),
+		Member: strings.Join([]string{listKey(queueName, priority), jobId}, "|"),
+	}).Err()
+}
+
+// PromoteDue enqueues up to limit scheduled jobs that are due at now and
+// returns how many were moved.
+func (q *Queue) PromoteDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	return promoteScript.Run(ctx, q.client,
+		[]string{scheduledKey, jobQueueKey},
+		strconv.FormatInt(now.UnixMilli(), 10),
+		limit,
+	).Int()
+}
+
+// Wakeup is a job to push onto its queue, or to schedule if RunAt is in the
+// future.
+type Wakeup struct {
+	JobID    string
+	Queue    string
+	Priority int
+	RunAt    time.Time
+}
+
+// EnqueueBatch pushes or schedules every wake-up in a single round trip,
+// with one LPUSH per list and one ZADD for the jobs that are not yet due.
+func (q *Queue) EnqueueBatch(ctx context.Context, wakeups []Wakeup, now time.Time) error {
+	lists := make(map[string][]any)
+	var order []string
+	var scheduled []redis.Z
+
+	for _, wk := range wakeups {
+		key := listKey(wk.Queue, wk.Priority)
+		if wk.RunAt.After(now) {
+			scheduled = append(scheduled, redis.Z{
+				Score:  float64(wk.RunAt.UnixMilli()),
+				Member: strings.Join([]string{key, wk.JobID}, "|"),
+			})
+			continue
+		}
+		if _, ok := lists[key]; !ok {
+			order = append(order, key)
+		}
+		lists[key] = append(lists[key], wk.JobID)
+	}
+
+	pipe := q.client.Pipeline()
+	for _, key := range order {
+		pipe.LPush(ctx, key, lists[key]...)
+	}
+	if len(scheduled) > 0 {
+		pipe.ZAdd(ctx, scheduledKey, scheduled...)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// scheduleBatch caps how many due schedules are claimed per transaction.
+const scheduleBatch = 100
+
+// CronScheduler turns due recurring schedules into jobs.
+type CronScheduler struct {
+	store *store.Store
+	queue *queue.Queue
+}
+
+func NewCronScheduler(store *store.Store, queue *queue.Queue) *CronScheduler {
+	return &CronScheduler{store: store, queue: queue}
+}
+
+func (cs *CronScheduler) Start() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cs.tick()
+	}
+}
+
+func (cs *CronScheduler) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for {
+		entries, err := cs.store.MaterializeDueSchedules(ctx, scheduleBatch)
+		if err != nil {
+			log.Println("Failed to materialize schedules:", err)
+			return
+		}
+
+		enqueueAll(ctx, cs.queue, entries)
+
+		if len(entries) < scheduleBatch {
+			return
+		}
+	}
+}
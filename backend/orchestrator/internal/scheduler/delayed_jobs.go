@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+)
+
+// promoteBatch caps how many due jobs are moved per Redis round trip.
+const promoteBatch = 500
+
+// DelayedJobPromoter moves jobs submitted with a future run_at onto the job
+// queue once they come due.
+type DelayedJobPromoter struct {
+	queue *queue.Queue
+}
+
+func NewDelayedJobPromoter(queue *queue.Queue) *DelayedJobPromoter {
+	return &DelayedJobPromoter{queue: queue}
+}
+
+func (dp *DelayedJobPromoter) Start() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		dp.promote()
+	}
+}
+
+func (dp *DelayedJobPromoter) promote() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for {
+		n, err := dp.queue.PromoteDue(ctx, time.Now(), promoteBatch)
+		if err != nil {
+			log.Println("Failed to promote delayed jobs:", err)
+			return
+		}
+		if n < promoteBatch {
+			return
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// enqueueAll pushes a wake-up for each entry onto its queue, or schedules it
+// when the entry is not due yet. Failures are only logged: the job is already
+// PENDING in Postgres and the next wake-up on its queue will still pick it up.
+func enqueueAll(ctx context.Context, q *queue.Queue, entries []store.QueueEntry) {
+	now := time.Now()
+	for _, entry := range entries {
+		var err error
+		if entry.RunAt.After(now) {
+			err = q.Schedule(ctx, entry.Queue, entry.ID.String(), entry.Priority, entry.RunAt)
+		} else {
+			err = q.Enqueue(ctx, entry.Queue, entry.ID.String(), entry.Priority)
+		}
+		if err != nil {
+			log.Println("Failed to enqueue job", entry.ID, ":", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+const (
+	// staleAfter is how long a due job may sit PENDING before it gets
+	// another wake-up.
+	staleAfter  = 30 * time.Second
+	rewakeBatch = 100
+)
+
+// PendingJobRewaker re-enqueues wake-ups for PENDING jobs nobody has picked
+// up. Workers only run the job types they registered, so a wake-up popped by
+// a worker that can't run the job is lost; this loop hands it back to the
+// queue until a capable worker takes the job.
+type PendingJobRewaker struct {
+	store *store.Store
+	queue *queue.Queue
+}
+
+func NewPendingJobRewaker(store *store.Store, queue *queue.Queue) *PendingJobRewaker {
+	return &PendingJobRewaker{store: store, queue: queue}
+}
+
+func (pr *PendingJobRewaker) Start() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		pr.rewake()
+	}
+}
+
+func (pr *PendingJobRewaker) rewake() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entries, err := pr.store.RewakeStalePendingJobs(ctx, staleAfter, rewakeBatch)
+	if err != nil {
+		log.Println("Failed to re-wake pending jobs:", err)
+		return
+	}
+
+	enqueueAll(ctx, pr.queue, entries)
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+// timeoutGrace is how long past its deadline a job may stay RUNNING before the
+// sweeper fails it; the worker normally reports its own timeout well within it.
+const timeoutGrace = 10 * time.Second
+
+type TimeoutSweeper struct {
+	store *store.Store
+	queue *queue.Queue
+}
+
+func NewTimeoutSweeper(store *store.Store, queue *queue.Queue) *TimeoutSweeper {
+	return &TimeoutSweeper{store: store, queue: queue}
+}
+
+func (ts *TimeoutSweeper) Start() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ts.sweep()
+	}
+}
+
+func (ts *TimeoutSweeper) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entries, err := ts.store.ExpireTimedOutJobs(ctx, timeoutGrace)
+	if err != nil {
+		log.Println("Failed to expire timed out jobs:", err)
+	}
+
+	enqueueAll(ctx, ts.queue, entries)
+}
@@ -5,15 +5,17 @@ import (
 	"log"
 	"time"
 
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/queue"
 	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
 )
 
 type WorkerMonitor struct {
 	store *store.Store
+	queue *queue.Queue
 }
 
-func NewWorkerMonitor(store *store.Store) *WorkerMonitor {
-	return &WorkerMonitor{store: store}
+func NewWorkerMonitor(store *store.Store, queue *queue.Queue) *WorkerMonitor {
+	return &WorkerMonitor{store: store, queue: queue}
 }
 
 func (wm *WorkerMonitor) Start() {
@@ -27,5 +29,21 @@ func (wm *WorkerMonitor) Start() {
 			log.Println("Failed to mark offline workers:", err)
 		}
 		cancel()
+
+		wm.reclaimJobs()
 	}
-}
\ No newline at end of file
+}
+
+// reclaimJobs hands the work of dead workers back to the queue so a crashed
+// pod doesn't leave its jobs RUNNING forever.
+func (wm *WorkerMonitor) reclaimJobs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entries, err := wm.store.ReclaimOrphanedJobs(ctx)
+	if err != nil {
+		log.Println("Failed to reclaim jobs from offline workers:", err)
+	}
+
+	enqueueAll(ctx, wm.queue, entries)
+}
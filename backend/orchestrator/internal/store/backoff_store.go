@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/backoff"
+)
+
+// policyJSON encodes a policy for a nullable JSONB column.
+func policyJSON(p *backoff.Policy) any {
+	if p == nil {
+		return nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// parsePolicy decodes a stored policy, falling back to backoff.Default when
+// there is none or it doesn't decode into a valid policy.
+func parsePolicy(raw []byte) backoff.Policy {
+	if raw == nil {
+		return backoff.Default
+	}
+
+	var p backoff.Policy
+	if err := json.Unmarshal(raw, &p); err != nil || p.Validate() != nil {
+		log.Println("Ignoring invalid backoff policy:", string(raw))
+		return backoff.Default
+	}
+	return p
+}
+
+// GetTypeBackoff returns the backoff policy of a job type, or nil if it has
+// none.
+func (s *Store) GetTypeBackoff(ctx context.Context, jobType string) (*backoff.Policy, error) {
+	var raw []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT policy FROM job_type_backoff WHERE type = $1`,
+		jobType,
+	).Scan(&raw)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := parsePolicy(raw)
+	return &p, nil
+}
+
+// SetTypeBackoff sets the backoff policy used by jobs of a type that don't
+// carry their own.
+func (s *Store) SetTypeBackoff(ctx context.Context, jobType string, p backoff.Policy) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_type_backoff (type, policy)
+		VALUES ($1, $2)
+		ON CONFLICT (type) DO UPDATE SET policy = EXCLUDED.policy, updated_at = NOW()
+	`, jobType, policyJSON(&p))
+	return err
+}
+
+// DeleteTypeBackoff reverts a job type to the default policy.
+func (s *Store) DeleteTypeBackoff(ctx context.Context, jobType string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM job_type_backoff WHERE type = $1`, jobType)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -7,11 +7,13 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func New() (*Store, error) {
+// New connects to DATABASE_URL. render builds the bodies of webhook
+// deliveries.
+func New(render JobRenderer) (*Store, error) {
 	dsn := os.Getenv("DATABASE_URL")
 	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, err
 	}
-	return &Store{db: db}, db.Ping()
+	return &Store{db: db, render: render}, db.Ping()
 }
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errorSignatureExpr normalises a job's error for grouping: ids and numbers
+// are masked so failures that only differ in those end up together, and the
+// result is cut to a manageable length.
+const errorSignatureExpr = `left(
+	regexp_replace(
+		regexp_replace(COALESCE(error, ''), '[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}', '<id>', 'gi'),
+		'[0-9]+', '<n>', 'g'
+	),
+	200
+)`
+
+// DeadLetterGroup summarises the DEAD jobs of one type that failed with the
+// same error signature.
+type DeadLetterGroup struct {
+	Type           string
+	ErrorSignature string
+	Count          int
+	FirstSeen      time.Time
+	LastSeen       time.Time
+	SampleJobID    uuid.UUID
+	SampleError    *string
+}
+
+// DeadLetterFilter selects DEAD jobs by type and error signature. Empty
+// fields match every job.
+type DeadLetterFilter struct {
+	Type           string
+	ErrorSignature string
+}
+
+// ListDeadLetterGroups groups the DEAD jobs by type and error signature,
+// largest group first, and returns up to limit groups along with the number
+// of DEAD jobs across all groups.
+func (s *Store) ListDeadLetterGroups(ctx context.Context, jobType string, limit int) ([]DeadLetterGroup, int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			type,
+			signature,
+			COUNT(*),
+			MIN(updated_at),
+			MAX(updated_at),
+			(array_agg(id ORDER BY updated_at DESC))[1],
+			(array_agg(error ORDER BY updated_at DESC))[1],
+			(SUM(COUNT(*)) OVER ())::bigint
+		FROM (
+			SELECT id, type, error, updated_at, `+errorSignatureExpr+` AS signature
+			FROM jobs
+			WHERE status = 'DEAD' AND ($1 = '' OR type = $1)
+		) dead
+		GROUP BY type, signature
+		ORDER BY COUNT(*) DESC, MAX(updated_at) DESC
+		LIMIT $2
+	`, jobType, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var groups []DeadLetterGroup
+	var total int
+	for rows.Next() {
+		var g DeadLetterGroup
+		if err := rows.Scan(
+			&g.Type,
+			&g.ErrorSignature,
+			&g.Count,
+			&g.FirstSeen,
+			&g.LastSeen,
+			&g.SampleJobID,
+			&g.SampleError,
+			&total,
+		); err != nil {
+			return nil, 0, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, total, rows.Err()
+}
+
+// PurgeDeadLetters deletes up to limit DEAD jobs matching filter, along with
+// their attempts, and returns how many jobs were deleted.
+func (s *Store) PurgeDeadLetters(ctx context.Context, filter DeadLetterFilter, limit int) (int, error) {
+	// the foreign key from job_attempts is checked at the end of the
+	// statement, after both deletes have run
+	var n int
+	err := s.db.QueryRowContext(ctx, `
+		WITH doomed AS (
+			SELECT id
+			FROM jobs
+			WHERE status = 'DEAD'
+				AND ($1 = '' OR type = $1)
+				AND ($2 = '' OR `+errorSignatureExpr+` = $2)
+			ORDER BY updated_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		), attempts AS (
+			DELETE FROM job_attempts WHERE job_id IN (SELECT id FROM doomed)
+		), deleted AS (
+			DELETE FROM jobs WHERE id IN (SELECT id FROM doomed)
+			RETURNING id
+		)
+		SELECT COUNT(*) FROM deleted
+	`, filter.Type, filter.ErrorSignature, limit).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobAttempt struct {
+	ID             uuid.UUID
+	AttemptNumber  int
+	Status         string
+	Error          *string
+	WorkerID       *uuid.UUID
+	WorkerHostname *string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+}
+
+// openAttempt records the start of a new execution of jobID on workerID.
+// Attempts are numbered from the job's history rather than its retry_count,
+// which a manual requeue may reset.
+func openAttempt(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, workerID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO job_attempts (id, job_id, attempt_number, status, worker_id, started_at)
+		SELECT $1, $2, COALESCE(MAX(attempt_number), 0) + 1, 'RUNNING', $3, NOW()
+		FROM job_attempts
+		WHERE job_id = $2
+	`, uuid.New(), jobID, workerID)
+	return err
+}
+
+// closeAttempt finishes whichever attempt of jobID is still open.
+func closeAttempt(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, status string, errMsg string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE job_attempts
+		SET status = $1, error = NULLIF($2, ''), finished_at = NOW()
+		WHERE job_id = $3 AND finished_at IS NULL
+	`, status, errMsg, jobID)
+	return err
+}
+
+func (s *Store) ListJobAttempts(ctx context.Context, jobID uuid.UUID) ([]JobAttempt, error) {
+	return listJobAttempts(ctx, s.db, jobID)
+}
+
+func listJobAttempts(ctx context.Context, q execer, jobID uuid.UUID) ([]JobAttempt, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT
+			a.id,
+			a.attempt_number,
+			a.status,
+			a.error,
+			a.worker_id,
+			w.hostname,
+			a.started_at,
+			a.finished_at
+		FROM job_attempts a
+		LEFT JOIN workers w ON w.id = a.worker_id
+		WHERE a.job_id = $1
+		ORDER BY a.attempt_number, a.started_at
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []JobAttempt
+	for rows.Next() {
+		var a JobAttempt
+		if err := rows.Scan(
+			&a.ID,
+			&a.AttemptNumber,
+			&a.Status,
+			&a.Error,
+			&a.WorkerID,
+			&a.WorkerHostname,
+			&a.StartedAt,
+			&a.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// CancelJob stops a job and returns its status afterwards, or "" if there is
+// no such job. A PENDING or BLOCKED job is CANCELLED right away, along with
+// the jobs depending on it; group callbacks this releases are returned for
+// enqueueing. A RUNNING job is only flagged: it stays RUNNING until its
+// worker, told through the heartbeat, reports the cancellation. Jobs in any
+// other status are left untouched.
+func (s *Store) CancelJob(ctx context.Context, jobID uuid.UUID) (string, []QueueEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx,
+		`SELECT status FROM jobs WHERE id = $1 FOR UPDATE`,
+		jobID,
+	).Scan(&status)
+
+	if err == sql.ErrNoRows {
+		return "", nil, nil
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	var released []QueueEntry
+	switch status {
+	case "PENDING", "BLOCKED":
+		_, err = tx.ExecContext(ctx,
+			`UPDATE jobs SET status = 'CANCELLED', cancel_requested = TRUE, error = 'cancelled', updated_at = NOW() WHERE id = $1`,
+			jobID,
+		)
+		if err == nil {
+			released, err = s.finishJob(ctx, tx, jobID, status, "CANCELLED")
+		}
+		status = "CANCELLED"
+	case "RUNNING":
+		_, err = tx.ExecContext(ctx,
+			`UPDATE jobs SET cancel_requested = TRUE WHERE id = $1`,
+			jobID,
+		)
+	default:
+		return status, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return status, released, tx.Commit()
+}
+
+// CancelledRunningJobs lists the RUNNING jobs of a worker that have been
+// asked to stop.
+func (s *Store) CancelledRunningJobs(ctx context.Context, workerID uuid.UUID) ([]uuid.UUID, error) {
+	return s.queryIDs(ctx, `
+		SELECT id
+		FROM jobs
+		WHERE worker_id = $1 AND status = 'RUNNING' AND cancel_requested
+	`, workerID)
+}
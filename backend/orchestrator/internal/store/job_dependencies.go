@@ -0,0 +1,331 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownDependency is returned when a job depends on a job that does not
+// exist.
+var ErrUnknownDependency = errors.New("unknown dependency")
+
+// linkDependencies records the parents of a freshly inserted job and moves
+// it out of BLOCKED if they already decide its fate: PENDING when they all
+// succeeded, DEAD or CANCELLED when one of them did. The parent rows are
+// share-locked, so a parent finishing concurrently waits for the edges to be
+// committed and then sees them when it promotes or fails its dependents.
+func (s *Store) linkDependencies(ctx context.Context, tx *sql.Tx, job *JobCreate) error {
+	if len(job.DependsOn) == 0 {
+		return nil
+	}
+
+	parents := uuidStrings(job.DependsOn)
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, status FROM jobs WHERE id = ANY($1::uuid[]) ORDER BY id FOR SHARE`,
+		parents,
+	)
+	if err != nil {
+		return err
+	}
+
+	statuses := make(map[uuid.UUID]string, len(parents))
+	for rows.Next() {
+		var id uuid.UUID
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return err
+		}
+		statuses[id] = status
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	status, errMsg := "PENDING", ""
+	for _, parent := range job.DependsOn {
+		switch parentStatus, ok := statuses[parent]; {
+		case !ok:
+			return fmt.Errorf("%w: %s", ErrUnknownDependency, parent)
+		case parentStatus == "DEAD" || parentStatus == "CANCELLED":
+			status, errMsg = parentStatus, dependencyError(parent, parentStatus)
+		case parentStatus != "SUCCESS" && status == "PENDING":
+			status = "BLOCKED"
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO job_dependencies (job_id, depends_on)
+		SELECT $1, parent FROM unnest($2::uuid[]) AS parent
+		ON CONFLICT DO NOTHING
+	`, job.ID, parents)
+	if err != nil {
+		return err
+	}
+
+	if status == "BLOCKED" {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE jobs SET status = $1, error = NULLIF($2, ''), updated_at = NOW() WHERE id = $3`,
+		status,
+		errMsg,
+		job.ID,
+	)
+	if err != nil {
+		return err
+	}
+	job.Status = status
+	return s.queueWebhooks(ctx, tx, []uuid.UUID{job.ID}, status)
+}
+
+// finishJob runs inside the transaction that moves a job from one status to
+// a final one. Success unblocks the dependents whose parents have now all
+// succeeded; DEAD and CANCELLED are passed down to every blocked descendant.
+// The job's group counters and workflow are brought up to date, and webhooks
+// are queued for jobs that ended up SUCCESS or DEAD. It returns
+// the jobs that became runnable: unblocked dependents and the callbacks of
+// completed groups.
+func (s *Store) finishJob(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, from, to string) ([]QueueEntry, error) {
+	var runnable, callbacks []QueueEntry
+	var cascaded []uuid.UUID
+	var err error
+	switch to {
+	case "SUCCESS":
+		runnable, err = promoteDependents(ctx, tx, jobID)
+	case "DEAD", "CANCELLED":
+		cascaded, err = cascadeToDependents(ctx, tx, jobID, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// cascaded jobs were BLOCKED, so they usually leave the same counter as
+	// the job itself and their groups can be updated in one go
+	ids := []uuid.UUID{jobID}
+	if groupCounter(from) == groupCounter("BLOCKED") {
+		ids = append(ids, cascaded...)
+	} else if len(cascaded) > 0 {
+		callbacks, err = moveGroupCounts(ctx, tx, cascaded, "BLOCKED", to)
+		if err != nil {
+			return nil, err
+		}
+		runnable = append(runnable, callbacks...)
+	}
+
+	callbacks, err = moveGroupCounts(ctx, tx, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	runnable = append(runnable, callbacks...)
+
+	if err := s.queueWebhooks(ctx, tx, append([]uuid.UUID{jobID}, cascaded...), to); err != nil {
+		return nil, err
+	}
+
+	return runnable, refreshWorkflows(ctx, tx, []uuid.UUID{jobID})
+}
+
+func promoteDependents(ctx context.Context, tx *sql.Tx, jobID uuid.UUID) ([]QueueEntry, error) {
+	// locking the children first serialises parents finishing at the same
+	// time: whichever commits last sees the other's success
+	children, err := txIDs(ctx, tx, `
+		SELECT j.id
+		FROM jobs j
+		JOIN job_dependencies d ON d.job_id = j.id
+		WHERE d.depends_on = $1 AND j.status = 'BLOCKED'
+		ORDER BY j.id
+		FOR UPDATE OF j
+	`, jobID)
+	if err != nil || len(children) == 0 {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE jobs j
+		SET status = 'PENDING', updated_at = NOW()
+		WHERE j.id = ANY($1::uuid[])
+			AND j.status = 'BLOCKED'
+			AND NOT EXISTS (
+				SELECT 1
+				FROM job_dependencies d
+				JOIN jobs p ON p.id = d.depends_on
+				WHERE d.job_id = j.id AND p.status <> 'SUCCESS'
+			)
+		RETURNING j.id, j.queue, j.priority, j.run_at
+	`, uuidStrings(children))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []QueueEntry
+	for rows.Next() {
+		var e QueueEntry
+		if err := rows.Scan(&e.ID, &e.Queue, &e.Priority, &e.RunAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// cascadeToDependents moves every BLOCKED descendant of a job to status and
+// returns them.
+func cascadeToDependents(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, status string) ([]uuid.UUID, error) {
+	errMsg := dependencyError(jobID, status)
+	frontier := []uuid.UUID{jobID}
+
+	var cascaded []uuid.UUID
+	for len(frontier) > 0 {
+		next, err := txIDs(ctx, tx, `
+			UPDATE jobs j
+			SET status = $2, error = $3, updated_at = NOW()
+			FROM job_dependencies d
+			WHERE d.job_id = j.id
+				AND d.depends_on = ANY($1::uuid[])
+				AND j.status = 'BLOCKED'
+			RETURNING j.id
+		`, uuidStrings(frontier), status, errMsg)
+		if err != nil {
+			return nil, err
+		}
+		cascaded = append(cascaded, next...)
+		frontier = next
+	}
+	return cascaded, nil
+}
+
+// JobGraph is the part of the dependency graph a job belongs to: its
+// ancestors, its descendants and the edges between them. Edges point from a
+// parent to the job depending on it.
+type JobGraph struct {
+	Nodes     []GraphNode
+	Edges     []GraphEdge
+	Truncated bool
+}
+
+type GraphNode struct {
+	ID     uuid.UUID
+	Type   string
+	Status string
+}
+
+type GraphEdge struct {
+	From uuid.UUID
+	To   uuid.UUID
+}
+
+// GetJobGraph returns the dependency graph around a job, or nil if there is
+// no such job. At most limit nodes are returned, the job itself first and the
+// rest in creation order; Truncated reports whether any were left out.
+func (s *Store) GetJobGraph(ctx context.Context, jobID uuid.UUID, limit int) (*JobGraph, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH RECURSIVE up(id) AS (
+			SELECT $1::uuid
+			UNION
+			SELECT d.depends_on FROM job_dependencies d JOIN up ON d.job_id = up.id
+		), down(id) AS (
+			SELECT $1::uuid
+			UNION
+			SELECT d.job_id FROM job_dependencies d JOIN down ON d.depends_on = down.id
+		), nodes AS (
+			SELECT id FROM up
+			UNION
+			SELECT id FROM down
+		)
+		SELECT j.id, j.type, j.status
+		FROM jobs j
+		JOIN nodes n ON n.id = j.id
+		ORDER BY j.id = $1 DESC, j.created_at, j.id
+		LIMIT $2
+	`, jobID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	graph := &JobGraph{}
+	for rows.Next() {
+		var n GraphNode
+		if err := rows.Scan(&n.ID, &n.Type, &n.Status); err != nil {
+			return nil, err
+		}
+		graph.Nodes = append(graph.Nodes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(graph.Nodes) == 0 {
+		return nil, nil
+	}
+	if len(graph.Nodes) > limit {
+		graph.Nodes = graph.Nodes[:limit]
+		graph.Truncated = true
+	}
+
+	ids := make([]uuid.UUID, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		ids[i] = n.ID
+	}
+
+	edges, err := s.db.QueryContext(ctx, `
+		SELECT depends_on, job_id
+		FROM job_dependencies
+		WHERE job_id = ANY($1::uuid[]) AND depends_on = ANY($1::uuid[])
+		ORDER BY depends_on, job_id
+	`, uuidStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer edges.Close()
+
+	for edges.Next() {
+		var e GraphEdge
+		if err := edges.Scan(&e.From, &e.To); err != nil {
+			return nil, err
+		}
+		graph.Edges = append(graph.Edges, e)
+	}
+	return graph, edges.Err()
+}
+
+func dependencyError(parent uuid.UUID, status string) string {
+	if status == "CANCELLED" {
+		return fmt.Sprintf("dependency %s was cancelled", parent)
+	}
+	return fmt.Sprintf("dependency %s failed", parent)
+}
+
+func txIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"github.com/google/uuid"
 	"time"
@@ -15,26 +16,50 @@ type JobDetail struct {
 	RetryCount     int
 	MaxRetries     int
 	TimeoutSeconds int
+	Priority       int
+	Queue          string
 	WorkerID       *uuid.UUID
 	Error          *string
+	RunAt          time.Time
+	Backoff        json.RawMessage
+	Result         json.RawMessage
+	ResultRef      *string
+	CallbackURL    *string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
 
 func (s *Store) GetJobDetail(ctx context.Context, jobId uuid.UUID) (*JobDetail, error) {
-	query := `SELECT * FROM jobs WHERE id = $1`
-	row, err := s.db.QueryContext(ctx, query, jobId)
-	if err != nil {
-		return nil, err
-	}
-	defer row.Close()
-
-	if !row.Next() {
-		return nil, nil
-	}
+	return getJobDetail(ctx, s.db, jobId)
+}
 
+func getJobDetail(ctx context.Context, q execer, jobId uuid.UUID) (*JobDetail, error) {
+	query := `
+		SELECT
+			id,
+			type,
+			payload,
+			status,
+			retry_count,
+			max_retries,
+			timeout_seconds,
+			priority,
+			queue,
+			worker_id,
+			error,
+			run_at,
+			backoff,
+			result,
+			result_ref,
+			callback_url,
+			created_at,
+			updated_at
+		FROM jobs
+		WHERE id = $1
+	`
 	var job JobDetail
-	if err := row.Scan(
+	var backoff, result []byte
+	err := q.QueryRowContext(ctx, query, jobId).Scan(
 		&job.ID,
 		&job.Type,
 		&job.Payload,
@@ -42,13 +67,51 @@ func (s *Store) GetJobDetail(ctx context.Context, jobId uuid.UUID) (*JobDetail,
 		&job.RetryCount,
 		&job.MaxRetries,
 		&job.TimeoutSeconds,
+		&job.Priority,
+		&job.Queue,
 		&job.WorkerID,
 		&job.Error,
+		&job.RunAt,
+		&backoff,
+		&result,
+		&job.ResultRef,
+		&job.CallbackURL,
 		&job.CreatedAt,
 		&job.UpdatedAt,
-	); err != nil {
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
+	job.Backoff = backoff
+	job.Result = result
 
 	return &job, nil
 }
+
+// GetJobResult returns the stored result of a job. found is false when there
+// is no such job; a job without a result has neither data nor a reference.
+func (s *Store) GetJobResult(ctx context.Context, jobId uuid.UUID) (result JobResult, found bool, err error) {
+	var data []byte
+	var ref *string
+	err = s.db.QueryRowContext(ctx,
+		`SELECT result, result_ref FROM jobs WHERE id = $1`,
+		jobId,
+	).Scan(&data, &ref)
+
+	if err == sql.ErrNoRows {
+		return JobResult{}, false, nil
+	}
+
+	if err != nil {
+		return JobResult{}, false, err
+	}
+
+	result.Data = data
+	if ref != nil {
+		result.Ref = *ref
+	}
+	return result, true, nil
+}
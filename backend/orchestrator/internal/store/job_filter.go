@@ -0,0 +1,64 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobFilter narrows ListJobs. Zero fields don't filter; Statuses matches any
+// of the given statuses and ErrorContains is a case-insensitive substring of
+// the job's error.
+type JobFilter struct {
+	Statuses      []string
+	Type          string
+	WorkerID      *uuid.UUID
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	ErrorContains string
+}
+
+// where renders the filter as a WHERE clause, or "" when it matches every
+// job. Its placeholders are numbered after the ones already in args, and the
+// returned slice has the filter's values appended.
+func (f JobFilter) where(args []any) (string, []any) {
+	var conds []string
+	add := func(format string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(format, len(args)))
+	}
+
+	if len(f.Statuses) > 0 {
+		add("status = ANY($%d::job_status[])", f.Statuses)
+	}
+	if f.Type != "" {
+		add("type = $%d", f.Type)
+	}
+	if f.WorkerID != nil {
+		add("worker_id = $%d", *f.WorkerID)
+	}
+	if f.CreatedAfter != nil {
+		add("created_at >= $%d", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		add("created_at < $%d", *f.CreatedBefore)
+	}
+	if f.UpdatedAfter != nil {
+		add("updated_at >= $%d", *f.UpdatedAfter)
+	}
+	if f.UpdatedBefore != nil {
+		add("updated_at < $%d", *f.UpdatedBefore)
+	}
+	if f.ErrorContains != "" {
+		add("error ILIKE '%%' || $%d || '%%'", f.ErrorContains)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,222 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupCreate is a set of jobs tracked together. The optional Callback job
+// is held back until every job of the group has finished, whatever the
+// outcome.
+type GroupCreate struct {
+	ID       uuid.UUID
+	Name     string
+	Jobs     []*JobCreate
+	Callback *JobCreate
+}
+
+// Group is the progress of a job group. Pending counts the jobs that have
+// not finished yet, retries included; CompletedAt is set once it drops to 0.
+type Group struct {
+	ID            uuid.UUID
+	Name          string
+	Total         int
+	Pending       int
+	Succeeded     int
+	Dead          int
+	Cancelled     int
+	CallbackJobID *uuid.UUID
+	CompletedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CreateGroup inserts a group, its jobs and its callback in one transaction.
+// The callback starts BLOCKED; if the group is already complete, because all
+// of its jobs depend on failed jobs, the callback is released right away and
+// returned for enqueueing.
+func (s *Store) CreateGroup(ctx context.Context, g *GroupCreate) ([]QueueEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var callbackID *uuid.UUID
+	if g.Callback != nil {
+		g.Callback.Status = "BLOCKED"
+		if _, err := insertJob(ctx, tx, g.Callback); err != nil {
+			return nil, err
+		}
+		callbackID = &g.Callback.ID
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO job_groups (id, name, total, pending, callback_job_id) VALUES ($1, $2, $3, $3, $4)`,
+		g.ID,
+		g.Name,
+		len(g.Jobs),
+		callbackID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range g.Jobs {
+		job.GroupID = &g.ID
+		if len(job.DependsOn) > 0 {
+			job.Status = "BLOCKED"
+		}
+	}
+
+	inserted := make(map[uuid.UUID]bool, len(g.Jobs))
+	for start := 0; start < len(g.Jobs); start += jobInsertChunk {
+		end := min(start+jobInsertChunk, len(g.Jobs))
+		if err := insertJobChunk(ctx, tx, g.Jobs[start:end], inserted); err != nil {
+			return nil, err
+		}
+	}
+
+	// jobs depending on failed jobs are DEAD or CANCELLED from the start
+	failed := make(map[string][]uuid.UUID)
+	for _, job := range g.Jobs {
+		if err := s.linkDependencies(ctx, tx, job); err != nil {
+			return nil, err
+		}
+		if job.Status == "DEAD" || job.Status == "CANCELLED" {
+			failed[job.Status] = append(failed[job.Status], job.ID)
+		}
+	}
+
+	var released []QueueEntry
+	for status, ids := range failed {
+		entries, err := moveGroupCounts(ctx, tx, ids, "PENDING", status)
+		if err != nil {
+			return nil, err
+		}
+		released = append(released, entries...)
+	}
+
+	return released, tx.Commit()
+}
+
+// GetGroup returns the progress of a group, or nil if there is no such
+// group.
+func (s *Store) GetGroup(ctx context.Context, id uuid.UUID) (*Group, error) {
+	g := &Group{ID: id}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT name, total, pending, succeeded, dead, cancelled, callback_job_id, completed_at, created_at, updated_at
+		FROM job_groups
+		WHERE id = $1
+	`, id).Scan(&g.Name, &g.Total, &g.Pending, &g.Succeeded, &g.Dead, &g.Cancelled, &g.CallbackJobID, &g.CompletedAt, &g.CreatedAt, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// groupCounter is the job_groups column counting jobs in status.
+func groupCounter(status string) string {
+	switch status {
+	case "SUCCESS":
+		return "succeeded"
+	case "DEAD":
+		return "dead"
+	case "CANCELLED":
+		return "cancelled"
+	}
+	return "pending"
+}
+
+// moveGroupCounts runs inside the transaction that moves jobs from one
+// status to another and shifts them between their groups' counters. Groups
+// left without pending jobs are completed, and the callbacks they release
+// are returned for enqueueing.
+func moveGroupCounts(ctx context.Context, tx *sql.Tx, ids []uuid.UUID, from, to string) ([]QueueEntry, error) {
+	src, dst := groupCounter(from), groupCounter(to)
+	if src == dst {
+		return nil, nil
+	}
+
+	// locking in id order keeps transactions touching several groups from
+	// deadlocking each other
+	groups, err := txIDs(ctx, tx, `
+		SELECT id
+		FROM job_groups
+		WHERE id IN (SELECT group_id FROM jobs WHERE id = ANY($1::uuid[]))
+		ORDER BY id
+		FOR UPDATE
+	`, uuidStrings(ids))
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE job_groups g
+		SET %[1]s = g.%[1]s - c.n, %[2]s = g.%[2]s + c.n, updated_at = NOW()
+		FROM (
+			SELECT group_id, COUNT(*) AS n
+			FROM jobs
+			WHERE id = ANY($1::uuid[]) AND group_id IS NOT NULL
+			GROUP BY group_id
+		) c
+		WHERE g.id = c.group_id
+	`, src, dst), uuidStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return completeGroups(ctx, tx, groups)
+}
+
+// completeGroups marks the given groups complete once nothing is pending and
+// releases their callbacks. A callback with an object payload gets the
+// group's outcome added under "group". Each group completes only once, even
+// if some of its jobs are requeued afterwards.
+func completeGroups(ctx context.Context, tx *sql.Tx, groups []uuid.UUID) ([]QueueEntry, error) {
+	rows, err := tx.QueryContext(ctx, `
+		WITH done AS (
+			UPDATE job_groups
+			SET completed_at = NOW()
+			WHERE id = ANY($1::uuid[]) AND pending = 0 AND completed_at IS NULL
+			RETURNING id, callback_job_id, total, succeeded, dead, cancelled
+		)
+		UPDATE jobs j
+		SET status = 'PENDING',
+			payload = CASE
+				WHEN jsonb_typeof(j.payload) = 'object' THEN j.payload || jsonb_build_object('group', jsonb_build_object(
+					'id', done.id,
+					'total', done.total,
+					'succeeded', done.succeeded,
+					'dead', done.dead,
+					'cancelled', done.cancelled
+				))
+				ELSE j.payload
+			END,
+			updated_at = NOW()
+		FROM done
+		WHERE j.id = done.callback_job_id AND j.status = 'BLOCKED'
+		RETURNING j.id, j.queue, j.priority, j.run_at
+	`, uuidStrings(groups))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []QueueEntry
+	for rows.Next() {
+		var e QueueEntry
+		if err := rows.Scan(&e.ID, &e.Queue, &e.Priority, &e.RunAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"time"
 	
 	"github.com/google/uuid"
@@ -12,27 +13,58 @@ type JobRow struct {
 	Type       string
 	Status     string
 	RetryCount int
+	Priority   int
+	Queue      string
 	WorkerID   *uuid.UUID
 	CreatedAt  time.Time
 }
 
+// JobCursor marks a position in the job list, which is ordered by
+// created_at and then id, newest first.
+type JobCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListJobs returns up to limit jobs matching filter, newest first. With a
+// cursor the page starts right after it and offset is ignored; offset paging
+// is kept for older clients and is unstable while jobs are being created.
 func (s *Store) ListJobs(
 	ctx context.Context,
+	filter JobFilter,
+	cursor *JobCursor,
 	limit int,
 	offset int,
 ) ([]JobRow, error) {
+	if cursor != nil {
+		offset = 0
+	}
+	where, args := filter.where([]any{limit, offset})
+	if cursor != nil {
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		cond := fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+		if where == "" {
+			where = "WHERE " + cond
+		} else {
+			where += " AND " + cond
+		}
+	}
+
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT
 			id,
 			type,
 			status,
 			retry_count,
+			priority,
+			queue,
 			worker_id,
 			created_at
 		FROM jobs
-		ORDER BY created_at DESC
+		`+where+`
+		ORDER BY created_at DESC, id DESC
 		LIMIT $1 OFFSET $2
-	`, limit, offset)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +78,8 @@ func (s *Store) ListJobs(
 			&job.Type,
 			&job.Status,
 			&job.RetryCount,
+			&job.Priority,
+			&job.Queue,
 			&job.WorkerID,
 			&job.CreatedAt,
 		); err != nil {
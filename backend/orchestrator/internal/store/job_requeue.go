@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// RequeueOptions controls how a finished job is put back into the queue.
+// Without ResetRetries or ExtraRetries the job keeps its retry count: one
+// that used up its retries gets one more attempt, one that died early, such
+// as on a permanent error, keeps the retries it had left.
+type RequeueOptions struct {
+	Reason       string
+	ResetRetries bool
+	ExtraRetries int
+}
+
+// RequeueFilter selects DEAD jobs for a bulk requeue. ErrorSignature matches
+// the normalised error shown by the dead-letter view. Empty fields match
+// every job.
+type RequeueFilter struct {
+	Type           string
+	ErrorContains  string
+	ErrorSignature string
+}
+
+// ErrFailedDependency is returned when a job can't be requeued because one
+// of its dependencies is DEAD or CANCELLED: its failure has already
+// been passed on, so nothing would ever release the job again.
+var ErrFailedDependency = errors.New("dependency has failed")
+
+// failedDependency matches the jobs, aliased c, with a dependency in a
+// failed final status.
+const failedDependency = `EXISTS (
+	SELECT 1
+	FROM job_dependencies d
+	JOIN jobs p ON p.id = d.depends_on
+	WHERE d.job_id = c.id AND p.status IN ('DEAD', 'CANCELLED')
+)`
+
+// RequeuedJob is a job put back by a requeue. Status is PENDING, or BLOCKED
+// when some of the job's dependencies have not succeeded (again) yet; only
+// PENDING jobs are to be enqueued.
+type RequeuedJob struct {
+	QueueEntry
+	Status string
+}
+
+// requeueQuery moves the jobs matched by the condition filled in at %s back
+// to PENDING, or BLOCKED while they wait on dependencies, and logs each of
+// them in job_requeues, in one statement. Jobs with a failed dependency are
+// left alone. $1 to $4 are the options and limit; the condition's own
+// arguments start at $5.
+const requeueQuery = `
+	WITH picked AS (
+		SELECT id, status, retry_count
+		FROM jobs c
+		WHERE (%s) AND NOT ` + failedDependency + `
+		ORDER BY updated_at
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	), requeued AS (
+		UPDATE jobs j
+		SET status = CASE
+				WHEN EXISTS (
+					SELECT 1
+					FROM job_dependencies d
+					JOIN jobs p ON p.id = d.depends_on
+					WHERE d.job_id = j.id AND p.status <> 'SUCCESS'
+				) THEN 'BLOCKED'::job_status
+				ELSE 'PENDING'::job_status
+			END,
+			retry_count = CASE WHEN $1 THEN 0 ELSE j.retry_count END,
+			max_retries = j.max_retries + $2,
+			worker_id = NULL,
+			cancel_requested = FALSE,
+			run_at = NOW(),
+			updated_at = NOW()
+		FROM picked
+		WHERE j.id = picked.id
+		RETURNING j.id, j.queue, j.priority, j.status, picked.status AS previous_status, picked.retry_count AS previous_retry_count
+	), logged AS (
+		INSERT INTO job_requeues (job_id, reason, previous_status, previous_retry_count, reset_retries, extra_retries)
+		SELECT id, $3, previous_status, previous_retry_count, $1, $2
+		FROM requeued
+	)
+	SELECT id, queue, priority, status, previous_status FROM requeued
+`
+
+// RequeueJob puts a DEAD or CANCELLED job back into PENDING. It returns the
+// requeued job together with its previous status; the job is nil when it is
+// in any other status, and the status is "" when there is no such job. A job
+// with a failed dependency is not requeued and ErrFailedDependency is
+// returned, naming that dependency. The job and its dependencies are locked
+// while they are checked, so a concurrent change waits rather than making the
+// requeue skip the job.
+func (s *Store) RequeueJob(ctx context.Context, jobID uuid.UUID, opts RequeueOptions) (*RequeuedJob, string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM jobs WHERE id = $1 FOR UPDATE`, jobID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if status != "DEAD" && status != "CANCELLED" {
+		return nil, status, nil
+	}
+
+	// share-locking the parents keeps them from failing between the check
+	// and the requeue
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.id, p.status
+		FROM job_dependencies d
+		JOIN jobs p ON p.id = d.depends_on
+		WHERE d.job_id = $1
+		ORDER BY p.id
+		FOR SHARE OF p
+	`, jobID)
+	if err != nil {
+		return nil, "", err
+	}
+	var failed error
+	for rows.Next() {
+		var parent uuid.UUID
+		var parentStatus string
+		if err := rows.Scan(&parent, &parentStatus); err != nil {
+			rows.Close()
+			return nil, "", err
+		}
+		if failed == nil && (parentStatus == "DEAD" || parentStatus == "CANCELLED") {
+			failed = fmt.Errorf("%w: %s is %s", ErrFailedDependency, parent, parentStatus)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	if failed != nil {
+		return nil, status, failed
+	}
+
+	entries, err := requeueTx(ctx, tx, opts, 1, `id = $5`, jobID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(entries) == 0 {
+		return nil, status, nil
+	}
+	return &entries[0], status, tx.Commit()
+}
+
+// RequeueDeadJobs puts up to limit DEAD jobs matching filter back into
+// PENDING, oldest first, and returns them. Jobs with a failed dependency are
+// skipped; they can be requeued once that dependency is.
+func (s *Store) RequeueDeadJobs(ctx context.Context, filter RequeueFilter, opts RequeueOptions, limit int) ([]RequeuedJob, error) {
+	return s.requeue(ctx, opts, limit, `
+		status = 'DEAD'
+			AND ($5 = '' OR type = $5)
+			AND ($6 = '' OR error ILIKE '%' || $6 || '%')
+			AND ($7 = '' OR `+errorSignatureExpr+` = $7)
+	`, filter.Type, filter.ErrorContains, filter.ErrorSignature)
+}
+
+func (s *Store) requeue(ctx context.Context, opts RequeueOptions, limit int, cond string, args ...any) ([]RequeuedJob, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entries, err := requeueTx(ctx, tx, opts, limit, cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	return entries, tx.Commit()
+}
+
+// requeueTx runs requeueQuery inside tx and brings the groups and workflows
+// of the requeued jobs up to date. Rows locked by tx itself are not skipped.
+func requeueTx(ctx context.Context, tx *sql.Tx, opts RequeueOptions, limit int, cond string, args ...any) ([]RequeuedJob, error) {
+	args = append([]any{opts.ResetRetries, opts.ExtraRetries, opts.Reason, limit}, args...)
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(requeueQuery, cond), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []RequeuedJob
+	var ids []uuid.UUID
+	previous := make(map[string][]uuid.UUID)
+	for rows.Next() {
+		var e RequeuedJob
+		var prev string
+		if err := rows.Scan(&e.ID, &e.Queue, &e.Priority, &e.Status, &prev); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		entries = append(entries, e)
+		ids = append(ids, e.ID)
+		previous[prev] = append(previous[prev], e.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// requeued jobs count as pending in their groups again; a completed
+	// group stays completed
+	for prev, group := range previous {
+		if _, err := moveGroupCounts(ctx, tx, group, prev, "PENDING"); err != nil {
+			return nil, err
+		}
+	}
+
+	// a failed workflow is running again once none of its steps is failed
+	if err := refreshWorkflows(ctx, tx, ids); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -4,34 +4,349 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/backoff"
 )
 
+// DefaultQueue is the queue of jobs submitted without one and of workers
+// that don't subscribe to any.
+const DefaultQueue = "default"
+
+// JobCreate is a job to insert. It is due at RunAt when that is set,
+// otherwise Delay after it is inserted as measured by the database clock,
+// which is also the clock assignment goes by. Inserting a delayed job sets
+// its RunAt; immediate jobs keep a zero RunAt, so they are enqueued right
+// away whatever the clock skew.
 type JobCreate struct {
-	ID             uuid.UUID
-	Type           string
-	Payload        json.RawMessage
-	Status         string
-	RetryCount     int
-	MaxRetries     int
-	TimeoutSeconds int
+	ID                uuid.UUID
+	Type              string
+	Payload           json.RawMessage
+	Status            string
+	RetryCount        int
+	MaxRetries        int
+	TimeoutSeconds    int
+	RunAt             time.Time
+	Delay             time.Duration
+	Priority          int
+	Queue             string
+	ScheduleID        *uuid.UUID
+	IdempotencyKey    string
+	Backoff           *backoff.Policy
+	DependsOn         []uuid.UUID
+	WorkflowID        *uuid.UUID
+	WorkflowStep      string
+	WorkflowStepIndex int
+	GroupID           *uuid.UUID
+	CallbackURL       string
+	CallbackSecret    string
+}
+
+// QueueEntry carries what a caller needs to push a job onto Redis after the
+// store has made it runnable again. A RunAt in the future means the job has
+// to be scheduled rather than enqueued.
+type QueueEntry struct {
+	ID       uuid.UUID
+	Queue    string
+	Priority int
+	RunAt    time.Time
+}
+
+// CreateJob inserts job and reports whether it did. A job whose
+// IdempotencyKey is already taken is not inserted again: job is instead
+// updated with the ID, status, run_at, queue and priority of the existing job
+// and created is false. A job with dependencies starts BLOCKED, or in the
+// status its parents already determine; job.Status is updated accordingly.
+func (s *Store) CreateJob(ctx context.Context, job *JobCreate) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if len(job.DependsOn) > 0 {
+		job.Status = "BLOCKED"
+	}
+
+	created, err := insertJob(ctx, tx, job)
+	if err != nil {
+		return false, err
+	}
+
+	if created {
+		err = s.linkDependencies(ctx, tx, job)
+	} else {
+		err = tx.QueryRowContext(ctx,
+			`SELECT id, status, run_at, queue, priority FROM jobs WHERE idempotency_key = $1`,
+			job.IdempotencyKey,
+		).Scan(&job.ID, &job.Status, &job.RunAt, &job.Queue, &job.Priority)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return created, tx.Commit()
 }
 
-func (s *Store) CreateJob(ctx context.Context, job *JobCreate) error {
-	_, err := s.db.ExecContext(ctx,
+// jobInsertChunk bounds the rows per INSERT statement in CreateJobs, keeping
+// the bind parameters well under Postgres' limit of 65535.
+const jobInsertChunk = 1000
+
+// CreateJobs inserts jobs in a single transaction and reports, for each job,
+// whether it was inserted. Jobs whose IdempotencyKey is already taken, by an
+// existing job or an earlier job of the same batch, are resolved like in
+// CreateJob, and so are dependencies. A job depending on a job that does not
+// exist is left out, with ErrUnknownDependency at its index in rejected; the
+// rest of the batch is still inserted.
+func (s *Store) CreateJobs(ctx context.Context, jobs []*JobCreate) (created []bool, rejected []error, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	rejected, err = unknownDependencies(ctx, tx, jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var valid []*JobCreate
+	for i, job := range jobs {
+		if rejected[i] != nil {
+			continue
+		}
+		if len(job.DependsOn) > 0 {
+			job.Status = "BLOCKED"
+		}
+		valid = append(valid, job)
+	}
+
+	inserted := make(map[uuid.UUID]bool, len(valid))
+	for start := 0; start < len(valid); start += jobInsertChunk {
+		end := min(start+jobInsertChunk, len(valid))
+		if err := insertJobChunk(ctx, tx, valid[start:end], inserted); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	created = make([]bool, len(jobs))
+	var replayedKeys []string
+	for i, job := range jobs {
+		if rejected[i] != nil {
+			continue
+		}
+		created[i] = inserted[job.ID]
+		if !created[i] {
+			replayedKeys = append(replayedKeys, job.IdempotencyKey)
+			continue
+		}
+		if err := s.linkDependencies(ctx, tx, job); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(replayedKeys) > 0 {
+		existing := make(map[string]JobCreate, len(replayedKeys))
+		rows, err := tx.QueryContext(ctx,
+			`SELECT idempotency_key, id, status, run_at, queue, priority FROM jobs WHERE idempotency_key = ANY($1)`,
+			replayedKeys,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var key string
+			var job JobCreate
+			if err := rows.Scan(&key, &job.ID, &job.Status, &job.RunAt, &job.Queue, &job.Priority); err != nil {
+				return nil, nil, err
+			}
+			existing[key] = job
+		}
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		for i, job := range jobs {
+			if created[i] || rejected[i] != nil {
+				continue
+			}
+			orig := existing[job.IdempotencyKey]
+			job.ID, job.Status, job.RunAt, job.Queue, job.Priority = orig.ID, orig.Status, orig.RunAt, orig.Queue, orig.Priority
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return created, rejected, nil
+}
+
+// unknownDependencies returns, at the index of each job, an
+// ErrUnknownDependency naming the first of its dependencies that does not
+// exist. The dependencies that do exist are share-locked, so they can't be
+// purged before the jobs are linked to them.
+func unknownDependencies(ctx context.Context, tx *sql.Tx, jobs []*JobCreate) ([]error, error) {
+	rejected := make([]error, len(jobs))
+
+	var parents []uuid.UUID
+	for _, job := range jobs {
+		parents = append(parents, job.DependsOn...)
+	}
+	if len(parents) == 0 {
+		return rejected, nil
+	}
+
+	found, err := txIDs(ctx, tx,
+		`SELECT id FROM jobs WHERE id = ANY($1::uuid[]) ORDER BY id FOR SHARE`,
+		uuidStrings(parents),
+	)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[uuid.UUID]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+
+	for i, job := range jobs {
+		for _, parent := range job.DependsOn {
+			if !exists[parent] {
+				rejected[i] = fmt.Errorf("%w: %s", ErrUnknownDependency, parent)
+				break
+			}
+		}
+	}
+	return rejected, nil
+}
+
+// insertJobChunk inserts jobs with one multi-row INSERT and records the ids
+// that were actually inserted.
+func insertJobChunk(ctx context.Context, tx *sql.Tx, jobs []*JobCreate, inserted map[uuid.UUID]bool) error {
+	const cols = 19
+
+	var b strings.Builder
+	b.WriteString(`INSERT INTO jobs (
+id, type, payload, status, max_retries, timeout_seconds, run_at, priority, queue, schedule_id, idempotency_key, backoff,
+workflow_id, workflow_step, workflow_step_index, group_id, callback_url, callback_secret
+) VALUES `)
+
+	args := make([]any, 0, len(jobs)*cols)
+	byID := make(map[uuid.UUID]*JobCreate, len(jobs))
+	for i, job := range jobs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		n := i * cols
+		fmt.Fprintf(&b, "($%d, $%d, $%d, $%d, $%d, $%d, COALESCE($%d, NOW() + $%d * INTERVAL '1 millisecond'), $%d, $%d, $%d, NULLIF($%d, ''), $%d, $%d, NULLIF($%d, ''), $%d, $%d, NULLIF($%d, ''), NULLIF($%d, ''))",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+12, n+13, n+14, n+15, n+16, n+17, n+18, n+19)
+		args = append(args,
+			job.ID,
+			job.Type,
+			job.Payload,
+			job.Status,
+			job.MaxRetries,
+			job.TimeoutSeconds,
+			nullTime(job.RunAt),
+			job.Delay.Milliseconds(),
+			job.Priority,
+			job.Queue,
+			job.ScheduleID,
+			job.IdempotencyKey,
+			policyJSON(job.Backoff),
+			job.WorkflowID,
+			job.WorkflowStep,
+			stepIndex(job),
+			job.GroupID,
+			job.CallbackURL,
+			job.CallbackSecret,
+		)
+		byID[job.ID] = job
+	}
+	b.WriteString(" ON CONFLICT (idempotency_key) DO NOTHING RETURNING id, run_at")
+
+	rows, err := tx.QueryContext(ctx, b.String(), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var runAt time.Time
+		if err := rows.Scan(&id, &runAt); err != nil {
+			return err
+		}
+		inserted[id] = true
+		if job := byID[id]; job.Delay > 0 {
+			job.RunAt = runAt
+		}
+	}
+	return rows.Err()
+}
+
+// insertJob inserts job unless its idempotency key is already taken, in
+// which case it returns false. A concurrent insert with the same key makes
+// it wait for that transaction rather than fail.
+func insertJob(ctx context.Context, db execer, job *JobCreate) (bool, error) {
+	var runAt time.Time
+	err := db.QueryRowContext(ctx,
 		`INSERT INTO jobs (
-id, type, payload, status, max_retries, timeout_seconds
-) VALUES ($1, $2, $3, $4, $5, $6)`,
+id, type, payload, status, max_retries, timeout_seconds, run_at, priority, queue, schedule_id, idempotency_key, backoff,
+callback_url, callback_secret
+) VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, NOW() + $8 * INTERVAL '1 millisecond'), $9, $10, $11, NULLIF($12, ''), $13, NULLIF($14, ''), NULLIF($15, ''))
+ON CONFLICT (idempotency_key) DO NOTHING
+RETURNING run_at`,
 		job.ID,
 		job.Type,
 		job.Payload,
 		job.Status,
 		job.MaxRetries,
 		job.TimeoutSeconds,
-	)
-	return err
+		nullTime(job.RunAt),
+		job.Delay.Milliseconds(),
+		job.Priority,
+		job.Queue,
+		job.ScheduleID,
+		job.IdempotencyKey,
+		policyJSON(job.Backoff),
+		job.CallbackURL,
+		job.CallbackSecret,
+	).Scan(&runAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if job.Delay > 0 {
+		job.RunAt = runAt
+	}
+	return true, nil
+}
+
+// stepIndex is the position of a workflow step among the submitted steps,
+// or NULL for a job outside any workflow.
+func stepIndex(job *JobCreate) any {
+	if job.WorkflowID == nil {
+		return nil
+	}
+	return job.WorkflowStepIndex
+}
+
+// nullTime is t, or NULL when t is zero.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
 }
 
 func (s *Store) AssignNextJob(ctx context.Context, workerID uuid.UUID) (*JobCreate, error) {
@@ -41,15 +356,64 @@ func (s *Store) AssignNextJob(ctx context.Context, workerID uuid.UUID) (*JobCrea
 	}
 	defer tx.Rollback()
 
+	// the worker row lock serialises concurrent fetches from the same worker
+	// so its slot count can't be overshot; only ONLINE workers get new jobs,
+	// DRAINING ones just finish what they have
+	var workerStatus string
+	var slots, running int
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, slots, (SELECT COUNT(*) FROM jobs WHERE worker_id = $1 AND status = 'RUNNING')
+		FROM workers
+		WHERE id = $1
+		FOR UPDATE
+	`, workerID).Scan(&workerStatus, &slots, &running)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if workerStatus != "ONLINE" || running >= slots {
+		return nil, nil
+	}
+
 	var job JobCreate
 
-	// query selects the oldest pending job from the jobs table for processing.
+	// query selects the highest priority pending job that is due from one of
+	// the worker's queues and of a type it can run, oldest first within a
+	// priority.
 	// It uses row-level locking (FOR UPDATE) to prevent concurrent access,
 	// and SKIP LOCKED to avoid waiting on already-locked rows, enabling
 	// multiple workers to efficiently pick up different pending jobs simultaneously.
-	query := `SELECT id, type, payload, retry_count, max_retries FROM jobs WHERE status = 'PENDING' ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED`
+	query := `
+		SELECT id, type, payload, retry_count, max_retries, timeout_seconds, priority, queue
+		FROM jobs
+		WHERE status = 'PENDING'
+			AND run_at <= NOW()
+			AND EXISTS (
+				SELECT 1 FROM workers w
+				WHERE w.id = $1
+					AND jobs.queue = ANY(w.queues)
+					AND (cardinality(w.job_types) = 0 OR jobs.type = ANY(w.job_types))
+			)
+		ORDER BY priority DESC, created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
 
-	err = tx.QueryRowContext(ctx, query).Scan(&job.ID, &job.Type, &job.Payload, &job.RetryCount, &job.MaxRetries)
+	err = tx.QueryRowContext(ctx, query, workerID).Scan(
+		&job.ID,
+		&job.Type,
+		&job.Payload,
+		&job.RetryCount,
+		&job.MaxRetries,
+		&job.TimeoutSeconds,
+		&job.Priority,
+		&job.Queue,
+	)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -69,6 +433,11 @@ func (s *Store) AssignNextJob(ctx context.Context, workerID uuid.UUID) (*JobCrea
 		return nil, err
 	}
 
+	err = openAttempt(ctx, tx, job.ID, workerID)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Println("Assigning job", job.ID, "to worker", workerID)
 
 	if err := tx.Commit(); err != nil {
@@ -78,41 +447,323 @@ func (s *Store) AssignNextJob(ctx context.Context, workerID uuid.UUID) (*JobCrea
 	return &job, nil
 }
 
-func (s *Store) ReportJobResult(ctx context.Context, jobID uuid.UUID, status string, errMsg string) error {
-	query := `UPDATE jobs SET status = $1, error = $2, updated_at = NOW() WHERE id = $3`
-	_, err := s.db.ExecContext(ctx, query, status, errMsg, jobID)
-	return err
+// JobResult is the output of a job: either the JSON result itself or, when
+// it was too large for the jobs table, a reference into the blob store.
+type JobResult struct {
+	Data json.RawMessage
+	Ref  string
 }
 
-func (s *Store) HandleJobFailures(ctx context.Context, jobId uuid.UUID, errormsg string) (error, bool) {
-	var retrycount, max_retries int
+// ErrStaleReport is returned when a worker reports on a job it no longer
+// runs: the job was reclaimed, timed out, cancelled or handed to another
+// worker since.
+var ErrStaleReport = errors.New("job is not running on this worker")
 
+// ReportJobResult records the final status of a job running on workerID.
+// Jobs this makes runnable, dependents and group callbacks, are returned for
+// enqueueing.
+func (s *Store) ReportJobResult(ctx context.Context, jobID, workerID uuid.UUID, status string, errMsg string, result JobResult) ([]QueueEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockRunningJob(ctx, tx, jobID, workerID); err != nil {
+		return nil, err
+	}
+
+	var data any
+	if len(result.Data) > 0 {
+		data = []byte(result.Data)
+	}
+
+	query := `UPDATE jobs SET status = $1, error = $2, result = $3, result_ref = NULLIF($4, ''), updated_at = NOW() WHERE id = $5`
+	_, err = tx.ExecContext(ctx, query, status, errMsg, data, result.Ref, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := closeAttempt(ctx, tx, jobID, status, errMsg); err != nil {
+		return nil, err
+	}
+
+	runnable, err := s.finishJob(ctx, tx, jobID, "RUNNING", status)
+	if err != nil {
+		return nil, err
+	}
+
+	return runnable, tx.Commit()
+}
+
+// lockRunningJob locks a job for the rest of tx and checks that it is
+// RUNNING on workerID, returning ErrStaleReport otherwise. The row lock
+// serialises a worker's report with the monitor reclaiming the same job, so
+// an attempt is only ever finished once.
+func lockRunningJob(ctx context.Context, tx *sql.Tx, jobID, workerID uuid.UUID) error {
+	var running bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT status = 'RUNNING' AND worker_id IS NOT DISTINCT FROM $2 FROM jobs WHERE id = $1 FOR UPDATE`,
+		jobID,
+		workerID,
+	).Scan(&running)
+	if err == sql.ErrNoRows || err == nil && !running {
+		return ErrStaleReport
+	}
+	return err
+}
+
+// JobRunsOn reports whether a job is currently RUNNING on workerID.
+func (s *Store) JobRunsOn(ctx context.Context, jobID, workerID uuid.UUID) (bool, error) {
+	var running bool
 	err := s.db.QueryRowContext(ctx,
-		`SELECT retry_count, max_retries FROM jobs WHERE id = $1`,
-		jobId,
-	).Scan(&retrycount, &max_retries)
+		`SELECT EXISTS (SELECT 1 FROM jobs WHERE id = $1 AND status = 'RUNNING' AND worker_id = $2)`,
+		jobID,
+		workerID,
+	).Scan(&running)
+	return running, err
+}
+
+// Failure describes a failed attempt of a job on WorkerID. Permanent
+// failures are never retried; a positive RetryAfter replaces the backoff
+// delay before the next attempt.
+type Failure struct {
+	WorkerID   uuid.UUID
+	Error      string
+	Permanent  bool
+	RetryAfter time.Duration
+}
 
+// HandleJobFailures records a failed attempt of a job RUNNING on the
+// failure's worker, and returns ErrStaleReport for any other job. The job
+// goes back to PENDING while it has retries left and the failure is not
+// permanent, in which case it is returned for enqueueing; otherwise it is
+// marked DEAD, and only the group callbacks this releases are returned.
+func (s *Store) HandleJobFailures(ctx context.Context, jobId uuid.UUID, failure Failure) ([]QueueEntry, error) {
+	errormsg := failure.Error
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err, false
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockRunningJob(ctx, tx, jobId, failure.WorkerID); err != nil {
+		return nil, err
+	}
+
+	var retrycount, max_retries, priority int
+	var queue string
+	var cancelRequested bool
+	var policy []byte
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT j.retry_count, j.max_retries, j.priority, j.queue, j.cancel_requested, COALESCE(j.backoff, t.policy)
+		FROM jobs j
+		LEFT JOIN job_type_backoff t ON t.type = j.type
+		WHERE j.id = $1
+	`, jobId).Scan(&retrycount, &max_retries, &priority, &queue, &cancelRequested, &policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := closeAttempt(ctx, tx, jobId, "FAILED", errormsg); err != nil {
+		return nil, err
+	}
+
+	// a job that was asked to stop is not retried, whatever made it fail
+	if cancelRequested {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE jobs SET status = 'CANCELLED', error = $1, updated_at = NOW() WHERE id = $2`,
+			errormsg,
+			jobId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		released, err := s.finishJob(ctx, tx, jobId, "RUNNING", "CANCELLED")
+		if err != nil {
+			return nil, err
+		}
+		return released, tx.Commit()
 	}
 
-	if retrycount+1 > max_retries {
-		_, err = s.db.ExecContext(ctx,
+	if failure.Permanent || retrycount+1 > max_retries {
+		_, err = tx.ExecContext(ctx,
 			`UPDATE jobs SET status = 'DEAD', error = $1, updated_at = NOW() WHERE id = $2`,
 			errormsg,
 			jobId,
 		)
-		return err, false
+		if err != nil {
+			return nil, err
+		}
+		released, err := s.finishJob(ctx, tx, jobId, "RUNNING", "DEAD")
+		if err != nil {
+			return nil, err
+		}
+		return released, tx.Commit()
 	}
 
-	_, err = s.db.ExecContext(ctx,
-		`UPDATE jobs SET status = 'PENDING', retry_count = retry_count + 1, error = $1, updated_at = NOW() WHERE id = $2`,
+	// the retry waits in PENDING until its backoff has passed, rather than
+	// holding up a worker slot
+	delay := failure.RetryAfter
+	if delay <= 0 {
+		delay = parsePolicy(policy).Delay(retrycount + 1)
+	}
+
+	// run_at is compared with the database clock when the job is assigned,
+	// so it is computed on that clock too
+	var runAt time.Time
+	err = tx.QueryRowContext(ctx,
+		`UPDATE jobs SET status = 'PENDING', retry_count = retry_count + 1, error = $1, run_at = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW() WHERE id = $3 RETURNING run_at`,
 		errormsg,
+		delay.Milliseconds(),
 		jobId,
-	)
+	).Scan(&runAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return []QueueEntry{{ID: jobId, Queue: queue, Priority: priority, RunAt: runAt}}, nil
+}
+
+// ReclaimOrphanedJobs fails every RUNNING job whose worker has gone OFFLINE,
+// applying the same retry/DEAD rules as a reported failure. It returns the
+// jobs that went back to PENDING, and callbacks released by jobs going DEAD,
+// so the caller can enqueue them.
+func (s *Store) ReclaimOrphanedJobs(ctx context.Context) ([]QueueEntry, error) {
+	jobs, err := s.queryRunningJobs(ctx, `
+		SELECT j.id, j.worker_id
+		FROM jobs j
+		JOIN workers w ON w.id = j.worker_id
+		WHERE j.status = 'RUNNING' AND w.status = 'OFFLINE'
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.failJobs(ctx, jobs, "worker went offline")
+}
+
+// ExpireTimedOutJobs fails RUNNING jobs that have been running longer than
+// their timeout_seconds plus grace. The grace period leaves the worker time to
+// report its own timeout before the orchestrator steps in.
+func (s *Store) ExpireTimedOutJobs(ctx context.Context, grace time.Duration) ([]QueueEntry, error) {
+	jobs, err := s.queryRunningJobs(ctx, `
+		SELECT id, worker_id
+		FROM jobs
+		WHERE status = 'RUNNING'
+			AND updated_at + (timeout_seconds + $1) * INTERVAL '1 second' < NOW()
+	`, int(grace.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.failJobs(ctx, jobs, "TIMEOUT: job exceeded its timeout")
+}
+
+// RewakeStalePendingJobs returns up to limit PENDING jobs that have been due
+// for longer than staleAfter without being picked up, and records when they
+// were woken so each is returned at most once per staleAfter. A wake-up can
+// be consumed by a worker that cannot run the job it was pushed for;
+// re-enqueueing these jobs makes sure a capable worker eventually sees them.
+func (s *Store) RewakeStalePendingJobs(ctx context.Context, staleAfter time.Duration, limit int) ([]QueueEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE jobs SET last_woken_at = NOW()
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE status = 'PENDING'
+				AND run_at <= NOW() - $1 * INTERVAL '1 second'
+				AND updated_at <= NOW() - $1 * INTERVAL '1 second'
+				AND (last_woken_at IS NULL OR last_woken_at <= NOW() - $1 * INTERVAL '1 second')
+			ORDER BY priority DESC, created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, queue, priority
+	`, int(staleAfter.Seconds()), limit)
 	if err != nil {
-		return err, false
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil, true
+	var entries []QueueEntry
+	for rows.Next() {
+		var e QueueEntry
+		if err := rows.Scan(&e.ID, &e.Queue, &e.Priority); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *Store) queryIDs(ctx context.Context, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// runningJob is an attempt of a job, identified by the worker running it.
+type runningJob struct {
+	ID       uuid.UUID
+	WorkerID uuid.UUID
+}
+
+func (s *Store) queryRunningJobs(ctx context.Context, query string, args ...any) ([]runningJob, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []runningJob
+	for rows.Next() {
+		var j runningJob
+		if err := rows.Scan(&j.ID, &j.WorkerID); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// failJobs runs each attempt through HandleJobFailures and collects the jobs
+// that were moved back to PENDING, along with any callbacks released.
+// Attempts that have finished in the meantime are skipped.
+func (s *Store) failJobs(ctx context.Context, jobs []runningJob, errormsg string) ([]QueueEntry, error) {
+	var retried []QueueEntry
+	for _, job := range jobs {
+		entries, err := s.HandleJobFailures(ctx, job.ID, Failure{WorkerID: job.WorkerID, Error: errormsg})
+		if errors.Is(err, ErrStaleReport) {
+			continue
+		}
+		if err != nil {
+			return retried, err
+		}
+		for _, e := range entries {
+			if e.ID == job.ID {
+				log.Println("Requeued job", job.ID, "after:", errormsg)
+			}
+		}
+		retried = append(retried, entries...)
+	}
+	return retried, nil
 }
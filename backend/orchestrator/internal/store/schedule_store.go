@@ -0,0 +1,305 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/cron"
+)
+
+type Schedule struct {
+	ID              uuid.UUID
+	Name            string
+	CronExpr        string
+	JobType         string
+	PayloadTemplate json.RawMessage
+	MaxRetries      int
+	TimeoutSeconds  int
+	Enabled         bool
+	NextRunAt       time.Time
+	LastRunAt       *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+const scheduleColumns = `
+	id,
+	name,
+	cron_expr,
+	job_type,
+	payload_template,
+	max_retries,
+	timeout_seconds,
+	enabled,
+	next_run_at,
+	last_run_at,
+	created_at,
+	updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSchedule(row rowScanner) (*Schedule, error) {
+	var sch Schedule
+	if err := row.Scan(
+		&sch.ID,
+		&sch.Name,
+		&sch.CronExpr,
+		&sch.JobType,
+		&sch.PayloadTemplate,
+		&sch.MaxRetries,
+		&sch.TimeoutSeconds,
+		&sch.Enabled,
+		&sch.NextRunAt,
+		&sch.LastRunAt,
+		&sch.CreatedAt,
+		&sch.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &sch, nil
+}
+
+func (s *Store) CreateSchedule(ctx context.Context, sch *Schedule) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO schedules (
+			id, name, cron_expr, job_type, payload_template,
+			max_retries, timeout_seconds, enabled, next_run_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		sch.ID,
+		sch.Name,
+		sch.CronExpr,
+		sch.JobType,
+		sch.PayloadTemplate,
+		sch.MaxRetries,
+		sch.TimeoutSeconds,
+		sch.Enabled,
+		sch.NextRunAt,
+	)
+	return err
+}
+
+func (s *Store) ListSchedules(ctx context.Context) ([]*Schedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+scheduleColumns+` FROM schedules ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*Schedule
+	for rows.Next() {
+		sch, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sch)
+	}
+	return schedules, rows.Err()
+}
+
+// GetSchedule returns nil, nil when no schedule has the given id.
+func (s *Store) GetSchedule(ctx context.Context, id uuid.UUID) (*Schedule, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, id)
+	sch, err := scanSchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sch, err
+}
+
+// UpdateSchedule overwrites the editable fields of a schedule and reports
+// whether it existed.
+func (s *Store) UpdateSchedule(ctx context.Context, sch *Schedule) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE schedules SET
+			name = $1,
+			cron_expr = $2,
+			job_type = $3,
+			payload_template = $4,
+			max_retries = $5,
+			timeout_seconds = $6,
+			enabled = $7,
+			next_run_at = $8,
+			updated_at = NOW()
+		WHERE id = $9
+	`,
+		sch.Name,
+		sch.CronExpr,
+		sch.JobType,
+		sch.PayloadTemplate,
+		sch.MaxRetries,
+		sch.TimeoutSeconds,
+		sch.Enabled,
+		sch.NextRunAt,
+		sch.ID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteSchedule removes a schedule and reports whether it existed. Jobs it
+// already created are kept.
+func (s *Store) DeleteSchedule(ctx context.Context, id uuid.UUID) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM schedules WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MaterializeDueSchedules creates one job for every enabled schedule whose
+// next_run_at has passed and advances the schedule to its following tick.
+// Schedules are claimed with FOR UPDATE SKIP LOCKED and advanced in the same
+// transaction as the job insert, so orchestrators running this concurrently
+// never materialize the same tick twice. Ticks missed while no orchestrator
+// was running collapse into a single catch-up job.
+// It returns the created jobs, which are due immediately.
+func (s *Store) MaterializeDueSchedules(ctx context.Context, limit int) ([]QueueEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM schedules
+		WHERE enabled AND next_run_at <= NOW()
+		ORDER BY next_run_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*Schedule
+	for rows.Next() {
+		sch, err := scanSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, sch)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// ticks are compared against the database clock above, so the next one
+	// must be computed from it too
+	var now time.Time
+	if err := tx.QueryRowContext(ctx, `SELECT NOW()`).Scan(&now); err != nil {
+		return nil, err
+	}
+
+	var created []QueueEntry
+	for _, sch := range due {
+		expr, err := cron.Parse(sch.CronExpr)
+		if err != nil {
+			log.Println("Disabling schedule", sch.ID, "with invalid cron expression:", err)
+			if _, err := tx.ExecContext(ctx, `UPDATE schedules SET enabled = FALSE, updated_at = NOW() WHERE id = $1`, sch.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		tick := sch.NextRunAt
+		job := &JobCreate{
+			ID:             uuid.New(),
+			Type:           sch.JobType,
+			Payload:        renderPayloadTemplate(sch.PayloadTemplate, sch.ID, tick),
+			Status:         "PENDING",
+			MaxRetries:     sch.MaxRetries,
+			TimeoutSeconds: sch.TimeoutSeconds,
+			RunAt:          tick,
+			Queue:          DefaultQueue,
+			ScheduleID:     &sch.ID,
+		}
+		inserted, err := insertScheduledJob(ctx, tx, job)
+		if err != nil {
+			return nil, err
+		}
+
+		// a schedule that can never fire again is switched off rather than
+		// left permanently due
+		from := now
+		if tick.After(from) {
+			from = tick
+		}
+		next := expr.Next(from)
+		enabled := !next.IsZero()
+		if !enabled {
+			next = tick
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE schedules
+			SET next_run_at = $1, last_run_at = $2, enabled = $3, updated_at = NOW()
+			WHERE id = $4
+		`, next, tick, enabled, sch.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if inserted {
+			created = append(created, QueueEntry{ID: job.ID, Queue: job.Queue, Priority: job.Priority})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// insertScheduledJob inserts the job for a schedule tick. It reports false
+// when the tick already has a job, so that the schedule can still move past
+// it instead of failing the whole batch on every pass.
+func insertScheduledJob(ctx context.Context, tx *sql.Tx, job *JobCreate) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO jobs (id, type, payload, status, max_retries, timeout_seconds, run_at, priority, queue, schedule_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (schedule_id, run_at) WHERE schedule_id IS NOT NULL DO NOTHING
+	`,
+		job.ID,
+		job.Type,
+		job.Payload,
+		job.Status,
+		job.MaxRetries,
+		job.TimeoutSeconds,
+		job.RunAt,
+		job.Priority,
+		job.Queue,
+		job.ScheduleID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// renderPayloadTemplate substitutes the {{schedule_id}} and {{scheduled_at}}
+// placeholders in a schedule's payload template. Both expand to plain
+// strings, so placeholders inside JSON strings keep the payload valid.
+func renderPayloadTemplate(tmpl json.RawMessage, scheduleID uuid.UUID, tick time.Time) json.RawMessage {
+	r := strings.NewReplacer(
+		"{{schedule_id}}", scheduleID.String(),
+		"{{scheduled_at}}", tick.UTC().Format(time.RFC3339),
+	)
+	return json.RawMessage(r.Replace(string(tmpl)))
+}
@@ -1,7 +1,24 @@
 package store
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 type Store struct {
-	db *sql.DB
+	db     *sql.DB
+	render JobRenderer
+}
+
+// JobRenderer renders a job and its attempts into the body of its webhook
+// deliveries. It runs inside the transaction that finishes the job, so the
+// body is the job as of the event.
+type JobRenderer func(job *JobDetail, attempts []JobAttempt) ([]byte, error)
+
+// execer is satisfied by both *sql.DB and *sql.Tx, so helpers can run
+// inside or outside a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
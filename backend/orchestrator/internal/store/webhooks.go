@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDelivery is a claimed delivery of a job's final state to its
+// callback URL. Attempt counts this attempt; Body is what to send.
+type WebhookDelivery struct {
+	ID      uuid.UUID
+	JobID   uuid.UUID
+	URL     string
+	Secret  string
+	Event   string
+	Attempt int
+	Body    []byte
+}
+
+// WebhookAttempt is the outcome of one delivery attempt. A zero StatusCode
+// means no response was received. A failed attempt is retried at RetryAt,
+// or given up for good when it is nil.
+type WebhookAttempt struct {
+	Delivered  bool
+	StatusCode int
+	Error      string
+	RetryAt    *time.Time
+}
+
+// WebhookDeliveryLog is a delivery as listed for a job: PENDING while it is
+// being tried, then DELIVERED or FAILED.
+type WebhookDeliveryLog struct {
+	ID             uuid.UUID
+	URL            string
+	Event          string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// queueWebhooks records a delivery for each of the jobs that has a callback
+// URL, once they reach SUCCESS or DEAD. The body is rendered here, so every
+// attempt sends the job as it was when it finished.
+func (s *Store) queueWebhooks(ctx context.Context, tx *sql.Tx, ids []uuid.UUID, status string) error {
+	if status != "SUCCESS" && status != "DEAD" {
+		return nil
+	}
+	hooked, err := txIDs(ctx, tx,
+		`SELECT id FROM jobs WHERE id = ANY($1::uuid[]) AND callback_url IS NOT NULL ORDER BY id`,
+		uuidStrings(ids),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range hooked {
+		body, err := s.jobBody(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (job_id, url, event, body)
+			SELECT id, callback_url, $2, $3
+			FROM jobs
+			WHERE id = $1
+		`, id, status, string(body))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jobBody renders a job the way its webhook deliveries send it.
+func (s *Store) jobBody(ctx context.Context, q execer, jobID uuid.UUID) ([]byte, error) {
+	job, err := getJobDetail(ctx, q, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+
+	attempts, err := listJobAttempts(ctx, q, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(job, attempts)
+}
+
+// ClaimWebhookDeliveries picks up to limit due deliveries and pushes their
+// next attempt lease into the future, so that concurrent dispatchers don't
+// send them twice and a crashed dispatcher's claims come back after lease.
+// Deliveries queued before bodies were stored get the job as it is now.
+func (s *Store) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+			next_attempt_at = NOW() + $2 * INTERVAL '1 second',
+			updated_at = NOW()
+		FROM jobs j
+		WHERE j.id = d.job_id
+			AND d.id IN (
+				SELECT id
+				FROM webhook_deliveries
+				WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+		RETURNING d.id, d.job_id, d.url, COALESCE(j.callback_secret, ''), d.event, d.attempts, d.body
+	`, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var body *string
+		if err := rows.Scan(&d.ID, &d.JobID, &d.URL, &d.Secret, &d.Event, &d.Attempt, &body); err != nil {
+			return nil, err
+		}
+		if body != nil {
+			d.Body = []byte(*body)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i, d := range deliveries {
+		if d.Body != nil {
+			continue
+		}
+		if deliveries[i].Body, err = s.jobBody(ctx, s.db, d.JobID); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt stores the outcome of a claimed delivery's attempt.
+func (s *Store) RecordWebhookAttempt(ctx context.Context, id uuid.UUID, attempt WebhookAttempt) error {
+	status := "PENDING"
+	switch {
+	case attempt.Delivered:
+		status = "DELIVERED"
+	case attempt.RetryAt == nil:
+		status = "FAILED"
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1,
+			last_status_code = NULLIF($2, 0),
+			last_error = NULLIF($3, ''),
+			next_attempt_at = COALESCE($4, next_attempt_at),
+			delivered_at = CASE WHEN $1 = 'DELIVERED' THEN NOW() END,
+			updated_at = NOW()
+		WHERE id = $5
+	`, status, attempt.StatusCode, attempt.Error, attempt.RetryAt, id)
+	return err
+}
+
+// ListWebhookDeliveries returns the deliveries of a job, oldest first.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, jobID uuid.UUID) ([]WebhookDeliveryLog, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, url, event, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE job_id = $1
+		ORDER BY created_at, id
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDeliveryLog
+	for rows.Next() {
+		var d WebhookDeliveryLog
+		if err := rows.Scan(&d.ID, &d.URL, &d.Event, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
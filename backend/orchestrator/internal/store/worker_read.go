@@ -2,8 +2,10 @@ package store
 
 import (
 	"context"
-	"github.com/google/uuid"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type WorkerRow struct {
@@ -11,10 +13,26 @@ type WorkerRow struct {
 	Hostname      string
 	Status        string
 	LastHeartbeat time.Time
+	Queues        []string
+	JobTypes      []string
+	Slots         int
+	RunningJobs   int
 }
 
 func (s *Store) ListWorkers(ctx context.Context) ([]*WorkerRow, error) {
-	query := `SELECT id, hostname, status, last_heartbeat FROM workers ORDER BY last_heartbeat DESC`
+	query := `
+		SELECT
+			w.id,
+			w.hostname,
+			w.status,
+			w.last_heartbeat,
+			array_to_json(w.queues),
+			array_to_json(w.job_types),
+			w.slots,
+			(SELECT COUNT(*) FROM jobs j WHERE j.worker_id = w.id AND j.status = 'RUNNING')
+		FROM workers w
+		ORDER BY w.last_heartbeat DESC
+	`
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -24,7 +42,14 @@ func (s *Store) ListWorkers(ctx context.Context) ([]*WorkerRow, error) {
 	var workers []*WorkerRow
 	for rows.Next() {
 		var w WorkerRow
-		if err := rows.Scan(&w.ID, &w.Hostname, &w.Status, &w.LastHeartbeat); err != nil {
+		var queues, jobTypes []byte
+		if err := rows.Scan(&w.ID, &w.Hostname, &w.Status, &w.LastHeartbeat, &queues, &jobTypes, &w.Slots, &w.RunningJobs); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(queues, &w.Queues); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(jobTypes, &w.JobTypes); err != nil {
 			return nil, err
 		}
 		workers = append(workers, &w)
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,28 +13,73 @@ type Worker struct {
 	Hostname      string
 	Status        string
 	LastHeartbeat time.Time
+	Queues        []string
+	JobTypes      []string
+	Slots         int
 }
 
-func (s *Store) CreateWorker(ctx context.Context, hostname string) (*Worker, error) {
+// WorkerRegistration is what a worker announces about itself on startup.
+// AssignNextJob only hands it jobs from its Queues whose type is one of its
+// JobTypes, an empty JobTypes meaning any type, and never more than Slots
+// jobs at a time.
+type WorkerRegistration struct {
+	Hostname string
+	Queues   []string
+	JobTypes []string
+	Slots    int
+}
+
+func (s *Store) CreateWorker(ctx context.Context, reg WorkerRegistration) (*Worker, error) {
 	worker := &Worker{
 		ID:            uuid.New(),
-		Hostname:      hostname,
+		Hostname:      reg.Hostname,
 		Status:        "ONLINE",
 		LastHeartbeat: time.Now(),
+		Queues:        reg.Queues,
+		JobTypes:      reg.JobTypes,
+		Slots:         reg.Slots,
+	}
+	if worker.JobTypes == nil {
+		worker.JobTypes = []string{}
 	}
 
-	query := `INSERT INTO workers (id, hostname, status, last_heartbeat) VALUES ($1, $2, $3, $4)`
-	_, err := s.db.ExecContext(ctx, query, worker.ID, worker.Hostname, worker.Status, worker.LastHeartbeat)
+	query := `INSERT INTO workers (id, hostname, status, last_heartbeat, queues, job_types, slots) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := s.db.ExecContext(ctx, query, worker.ID, worker.Hostname, worker.Status, worker.LastHeartbeat, worker.Queues, worker.JobTypes, worker.Slots)
 	if err != nil {
 		return nil, err
 	}
 	return worker, nil
 }
 
-func (s *Store) UpdateHeartbeat(ctx context.Context, workerID uuid.UUID) error {
-	query := `UPDATE workers SET last_heartbeat = $1, status = $2 WHERE id = $3`
-	_, err := s.db.ExecContext(ctx, query, time.Now(), "ONLINE", workerID)
-	return err
+// UpdateHeartbeat records a heartbeat and returns the worker's status. A
+// DRAINING worker stays DRAINING; any other worker is brought back ONLINE.
+// The status is empty if the worker is unknown.
+func (s *Store) UpdateHeartbeat(ctx context.Context, workerID uuid.UUID) (string, error) {
+	query := `
+		UPDATE workers
+		SET last_heartbeat = $1,
+			status = CASE WHEN status = 'DRAINING' THEN status ELSE $2 END
+		WHERE id = $3
+		RETURNING status
+	`
+	var status string
+	err := s.db.QueryRowContext(ctx, query, time.Now(), "ONLINE", workerID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
+
+// SetWorkerStatus moves a worker to status, e.g. DRAINING when it stops
+// taking new jobs or OFFLINE when it deregisters, and reports whether the
+// worker exists.
+func (s *Store) SetWorkerStatus(ctx context.Context, workerID uuid.UUID, status string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE workers SET status = $1 WHERE id = $2`, status, workerID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
 func (s *Store) MarkWorkerOffline(ctx context.Context, timeout time.Duration) error {
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowCreate is a named DAG of jobs submitted as one unit. The steps
+// must be in topological order, each job's DependsOn naming the jobs of
+// earlier steps; Index keeps the position each step was submitted at.
+type WorkflowCreate struct {
+	ID    uuid.UUID
+	Name  string
+	Steps []WorkflowStep
+}
+
+type WorkflowStep struct {
+	Name  string
+	Index int
+	Job   *JobCreate
+}
+
+type Workflow struct {
+	ID        uuid.UUID
+	Name      string
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Steps     []WorkflowStepState
+}
+
+type WorkflowStepState struct {
+	Name      string
+	JobID     uuid.UUID
+	Type      string
+	Status    string
+	Error     *string
+	DependsOn []string
+	UpdatedAt time.Time
+}
+
+// CreateWorkflow inserts a workflow and all of its jobs in one transaction.
+// Steps without dependencies start PENDING, the others BLOCKED; job.Status
+// of each step is updated accordingly.
+func (s *Store) CreateWorkflow(ctx context.Context, wf *WorkflowCreate) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO workflows (id, name, status) VALUES ($1, $2, 'RUNNING')`,
+		wf.ID,
+		wf.Name,
+	)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]*JobCreate, len(wf.Steps))
+	for i, step := range wf.Steps {
+		job := step.Job
+		job.WorkflowID = &wf.ID
+		job.WorkflowStep = step.Name
+		job.WorkflowStepIndex = step.Index
+		if len(job.DependsOn) > 0 {
+			job.Status = "BLOCKED"
+		}
+		jobs[i] = job
+	}
+
+	inserted := make(map[uuid.UUID]bool, len(jobs))
+	for start := 0; start < len(jobs); start += jobInsertChunk {
+		end := min(start+jobInsertChunk, len(jobs))
+		if err := insertJobChunk(ctx, tx, jobs[start:end], inserted); err != nil {
+			return err
+		}
+	}
+
+	for _, job := range jobs {
+		if err := s.linkDependencies(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetWorkflow returns a workflow with the state of each of its steps, in
+// submission order, or nil if there is no such workflow.
+func (s *Store) GetWorkflow(ctx context.Context, id uuid.UUID) (*Workflow, error) {
+	wf := &Workflow{ID: id}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT name, status, created_at, updated_at FROM workflows WHERE id = $1`,
+		id,
+	).Scan(&wf.Name, &wf.Status, &wf.CreatedAt, &wf.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			j.workflow_step,
+			j.id,
+			j.type,
+			j.status,
+			j.error,
+			COALESCE((
+				SELECT json_agg(p.workflow_step ORDER BY p.workflow_step)
+				FROM job_dependencies d
+				JOIN jobs p ON p.id = d.depends_on
+				WHERE d.job_id = j.id
+			), '[]'),
+			j.updated_at
+		FROM jobs j
+		WHERE j.workflow_id = $1
+		ORDER BY j.workflow_step_index
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var step WorkflowStepState
+		var dependsOn []byte
+		if err := rows.Scan(&step.Name, &step.JobID, &step.Type, &step.Status, &step.Error, &dependsOn, &step.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(dependsOn, &step.DependsOn); err != nil {
+			return nil, err
+		}
+		wf.Steps = append(wf.Steps, step)
+	}
+	return wf, rows.Err()
+}
+
+// refreshWorkflows recomputes the status of the workflows the given jobs
+// belong to: FAILED once a step is DEAD or CANCELLED, SUCCEEDED once every
+// step has succeeded, RUNNING otherwise. The workflow rows are locked first,
+// so steps finishing at the same time can't both miss the final transition.
+func refreshWorkflows(ctx context.Context, tx *sql.Tx, jobIDs []uuid.UUID) error {
+	workflows, err := txIDs(ctx, tx, `
+		SELECT id
+		FROM workflows
+		WHERE id IN (SELECT workflow_id FROM jobs WHERE id = ANY($1::uuid[]))
+		ORDER BY id
+		FOR UPDATE
+	`, uuidStrings(jobIDs))
+	if err != nil || len(workflows) == 0 {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE workflows w
+		SET status = s.status, updated_at = NOW()
+		FROM (
+			SELECT
+				id,
+				CASE
+					WHEN EXISTS (
+						SELECT 1 FROM jobs j
+						WHERE j.workflow_id = wf.id AND j.status IN ('DEAD', 'CANCELLED')
+					) THEN 'FAILED'
+					WHEN NOT EXISTS (
+						SELECT 1 FROM jobs j
+						WHERE j.workflow_id = wf.id AND j.status <> 'SUCCESS'
+					) THEN 'SUCCEEDED'
+					ELSE 'RUNNING'
+				END AS status
+			FROM workflows wf
+			WHERE wf.id = ANY($1::uuid[])
+		) s
+		WHERE w.id = s.id AND w.status <> s.status
+	`, uuidStrings(workflows))
+	return err
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/backoff"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+const (
+	dispatchBatch = 20
+	// claimLease must outlast a delivery attempt, or a slow receiver may get
+	// the same delivery twice
+	claimLease     = 2 * time.Minute
+	requestTimeout = 10 * time.Second
+	maxAttempts    = 30
+)
+
+// retryPolicy spaces out the attempts of a delivery: from 10s up to an hour
+// apart, so with jitter the maxAttempts span 11 to 21 hours before the
+// delivery is given up.
+var retryPolicy = backoff.Policy{
+	Strategy:    backoff.Exponential,
+	BaseSeconds: 10,
+	MaxSeconds:  3600,
+	Jitter:      true,
+}
+
+// Dispatcher sends the webhook deliveries queued by the store when jobs
+// finish. Each delivery POSTs the job's detail as GET /jobs/{id} returned it
+// when the job finished, and is retried on its own schedule until the
+// receiver answers with a 2xx status.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+}
+
+// Store is the part of *store.Store the dispatcher needs.
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]store.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, id uuid.UUID, attempt store.WebhookAttempt) error
+}
+
+// NewDispatcher returns a dispatcher sending through client, or through a
+// client with a 10 second timeout if it is nil.
+func NewDispatcher(store Store, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Dispatcher{store: store, client: client}
+}
+
+func (d *Dispatcher) Start() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.dispatch()
+	}
+}
+
+func (d *Dispatcher) dispatch() {
+	ctx, cancel := context.WithTimeout(context.Background(), claimLease)
+	defer cancel()
+
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, dispatchBatch, claimLease)
+	if err != nil {
+		log.Println("Failed to claim webhook deliveries:", err)
+		return
+	}
+
+	// one slow receiver must not hold up the others
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery store.WebhookDelivery) {
+	attempt := store.WebhookAttempt{}
+
+	var err error
+	attempt.StatusCode, err = d.Send(ctx, delivery)
+	switch {
+	case err != nil:
+		attempt.Error = err.Error()
+	case attempt.StatusCode < 200 || attempt.StatusCode > 299:
+		attempt.Error = fmt.Sprintf("unexpected status %d", attempt.StatusCode)
+	default:
+		attempt.Delivered = true
+	}
+
+	if !attempt.Delivered && delivery.Attempt < maxAttempts {
+		retryAt := time.Now().Add(retryPolicy.Delay(delivery.Attempt))
+		attempt.RetryAt = &retryAt
+	}
+	if !attempt.Delivered {
+		log.Println("Webhook delivery", delivery.ID, "for job", delivery.JobID, "failed:", attempt.Error)
+	}
+
+	if err := d.store.RecordWebhookAttempt(ctx, delivery.ID, attempt); err != nil {
+		log.Println("Failed to record webhook delivery", delivery.ID, ":", err)
+	}
+}
+
+// Send POSTs the delivery's body to its URL with the webhook headers, signed
+// when the job has a callback secret, and returns the response status.
+func (d *Dispatcher) Send(ctx context.Context, delivery store.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(TimestampHeader, fmt.Sprint(timestamp))
+	if delivery.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	return resp.StatusCode, nil
+}
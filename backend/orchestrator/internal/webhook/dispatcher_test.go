@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meanmachine889/distributed-orchestrator/orchestrator/internal/store"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{"signed", "s3cret"},
+		{"unsigned", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			delivery := store.WebhookDelivery{
+				ID:     uuid.New(),
+				JobID:  uuid.New(),
+				URL:    server.URL,
+				Secret: tt.secret,
+				Event:  "SUCCESS",
+				Body:   []byte(`{"status":"SUCCESS"}`),
+			}
+
+			code, err := NewDispatcher(nil, nil).Send(context.Background(), delivery)
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if code != http.StatusNoContent {
+				t.Errorf("status = %d, want %d", code, http.StatusNoContent)
+			}
+
+			if got.Method != http.MethodPost {
+				t.Errorf("method = %s, want POST", got.Method)
+			}
+			if string(gotBody) != string(delivery.Body) {
+				t.Errorf("body = %s, want %s", gotBody, delivery.Body)
+			}
+			if ct := got.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if id := got.Header.Get(DeliveryHeader); id != delivery.ID.String() {
+				t.Errorf("%s = %q, want %q", DeliveryHeader, id, delivery.ID)
+			}
+			if event := got.Header.Get(EventHeader); event != "SUCCESS" {
+				t.Errorf("%s = %q, want SUCCESS", EventHeader, event)
+			}
+
+			timestamp, err := strconv.ParseInt(got.Header.Get(TimestampHeader), 10, 64)
+			if err != nil {
+				t.Fatalf("%s: %v", TimestampHeader, err)
+			}
+			if age := time.Since(time.Unix(timestamp, 0)); age < -time.Second || age > time.Minute {
+				t.Errorf("%s is %v old", TimestampHeader, age)
+			}
+
+			signature := got.Header.Get(SignatureHeader)
+			if tt.secret == "" {
+				if signature != "" {
+					t.Errorf("%s = %q for a delivery without secret", SignatureHeader, signature)
+				}
+				return
+			}
+			if !Verify(tt.secret, timestamp, gotBody, signature) {
+				t.Errorf("%s = %q does not verify", SignatureHeader, signature)
+			}
+			if Verify("other", timestamp, gotBody, signature) {
+				t.Errorf("%s verifies with the wrong secret", SignatureHeader)
+			}
+		})
+	}
+}
+
+// fakeStore hands out its deliveries once and keeps the attempts recorded
+// for them.
+type fakeStore struct {
+	mu         sync.Mutex
+	deliveries []store.WebhookDelivery
+	attempts   map[uuid.UUID]store.WebhookAttempt
+}
+
+func (s *fakeStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]store.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claimed := s.deliveries
+	s.deliveries = nil
+	return claimed, nil
+}
+
+func (s *fakeStore) RecordWebhookAttempt(ctx context.Context, id uuid.UUID, attempt store.WebhookAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[id] = attempt
+	return nil
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		attempt   int
+		delivered bool
+		retry     bool
+	}{
+		{"delivered", http.StatusOK, 1, true, false},
+		{"server error is retried", http.StatusInternalServerError, 1, false, true},
+		{"client error is retried", http.StatusNotFound, 3, false, true},
+		{"given up after the last attempt", http.StatusBadGateway, maxAttempts, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			delivery := store.WebhookDelivery{
+				ID:      uuid.New(),
+				JobID:   uuid.New(),
+				URL:     server.URL,
+				Event:   "DEAD",
+				Attempt: tt.attempt,
+				Body:    []byte(`{}`),
+			}
+			fake := &fakeStore{
+				deliveries: []store.WebhookDelivery{delivery},
+				attempts:   make(map[uuid.UUID]store.WebhookAttempt),
+			}
+
+			before := time.Now()
+			NewDispatcher(fake, nil).dispatch()
+
+			got, ok := fake.attempts[delivery.ID]
+			if !ok {
+				t.Fatal("no attempt recorded")
+			}
+			if got.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", got.StatusCode, tt.status)
+			}
+			if got.Delivered != tt.delivered {
+				t.Errorf("Delivered = %v, want %v", got.Delivered, tt.delivered)
+			}
+			if tt.delivered != (got.Error == "") {
+				t.Errorf("Error = %q with Delivered %v", got.Error, got.Delivered)
+			}
+			if (got.RetryAt != nil) != tt.retry {
+				t.Fatalf("RetryAt = %v, want retry %v", got.RetryAt, tt.retry)
+			}
+			if tt.retry && !got.RetryAt.After(before) {
+				t.Errorf("RetryAt = %v, not after %v", got.RetryAt, before)
+			}
+		})
+	}
+}
+
+func TestDispatchUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	delivery := store.WebhookDelivery{ID: uuid.New(), URL: url, Event: "SUCCESS", Attempt: 1, Body: []byte(`{}`)}
+	fake := &fakeStore{
+		deliveries: []store.WebhookDelivery{delivery},
+		attempts:   make(map[uuid.UUID]store.WebhookAttempt),
+	}
+	NewDispatcher(fake, nil).dispatch()
+
+	got := fake.attempts[delivery.ID]
+	if got.Delivered || got.StatusCode != 0 || got.Error == "" || got.RetryAt == nil {
+		t.Errorf("attempt = %+v, want a retried failure without status", got)
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_job_attempts_job_id;
+ALTER TABLE job_attempts DROP COLUMN worker_id;
\ No newline at end of file
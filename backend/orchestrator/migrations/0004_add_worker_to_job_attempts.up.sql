@@ -0,0 +1,3 @@
+ALTER TABLE job_attempts ADD COLUMN worker_id UUID;
+
+CREATE INDEX idx_job_attempts_job_id ON job_attempts(job_id, attempt_number);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_pending_run_at;
+ALTER TABLE jobs DROP COLUMN run_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE jobs ADD COLUMN run_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_jobs_pending_run_at ON jobs(run_at) WHERE status = 'PENDING';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_schedule_tick;
+ALTER TABLE jobs DROP COLUMN schedule_id;
+DROP TABLE IF EXISTS schedules;
\ No newline at end of file
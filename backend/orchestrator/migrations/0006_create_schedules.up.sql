@@ -0,0 +1,21 @@
+CREATE TABLE schedules (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    cron_expr TEXT NOT NULL,
+    job_type TEXT NOT NULL,
+    payload_template JSONB NOT NULL DEFAULT '{}',
+    max_retries INT NOT NULL DEFAULT 3,
+    timeout_seconds INT NOT NULL DEFAULT 30,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_schedules_due ON schedules(next_run_at) WHERE enabled;
+
+ALTER TABLE jobs ADD COLUMN schedule_id UUID REFERENCES schedules(id) ON DELETE SET NULL;
+
+-- one job per schedule tick, even if two orchestrators race on the same tick
+CREATE UNIQUE INDEX idx_jobs_schedule_tick ON jobs(schedule_id, run_at) WHERE schedule_id IS NOT NULL;
\ No newline at end of file
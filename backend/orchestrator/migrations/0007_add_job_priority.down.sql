@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_pending_priority;
+CREATE INDEX idx_jobs_pending_run_at ON jobs(run_at) WHERE status = 'PENDING';
+ALTER TABLE jobs DROP COLUMN priority;
\ No newline at end of file
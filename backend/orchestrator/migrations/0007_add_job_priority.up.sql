@@ -0,0 +1,4 @@
+ALTER TABLE jobs ADD COLUMN priority INT NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS idx_jobs_pending_run_at;
+CREATE INDEX idx_jobs_pending_priority ON jobs(priority DESC, created_at) WHERE status = 'PENDING';
\ No newline at end of file
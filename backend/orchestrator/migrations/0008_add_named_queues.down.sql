@@ -0,0 +1,6 @@
+ALTER TABLE workers DROP COLUMN queues;
+
+DROP INDEX IF EXISTS idx_jobs_pending_queue;
+CREATE INDEX idx_jobs_pending_priority ON jobs(priority DESC, created_at) WHERE status = 'PENDING';
+
+ALTER TABLE jobs DROP COLUMN queue;
\ No newline at end of file
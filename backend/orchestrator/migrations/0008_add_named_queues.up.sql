@@ -0,0 +1,6 @@
+ALTER TABLE jobs ADD COLUMN queue TEXT NOT NULL DEFAULT 'default';
+
+DROP INDEX IF EXISTS idx_jobs_pending_priority;
+CREATE INDEX idx_jobs_pending_queue ON jobs(queue, priority DESC, created_at) WHERE status = 'PENDING';
+
+ALTER TABLE workers ADD COLUMN queues TEXT[] NOT NULL DEFAULT '{default}';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE workers DROP COLUMN job_types;
\ No newline at end of file
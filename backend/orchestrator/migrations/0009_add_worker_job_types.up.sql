@@ -0,0 +1 @@
+ALTER TABLE workers ADD COLUMN job_types TEXT[] NOT NULL DEFAULT '{}';
\ No newline at end of file
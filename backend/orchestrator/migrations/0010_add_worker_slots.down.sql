@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_running_worker;
+ALTER TABLE workers DROP COLUMN slots;
\ No newline at end of file
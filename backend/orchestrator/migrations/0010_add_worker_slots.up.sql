@@ -0,0 +1,3 @@
+ALTER TABLE workers ADD COLUMN slots INT NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_jobs_running_worker ON jobs(worker_id) WHERE status = 'RUNNING';
\ No newline at end of file
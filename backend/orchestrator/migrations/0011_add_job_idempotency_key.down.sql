@@ -0,0 +1 @@
+ALTER TABLE jobs DROP COLUMN idempotency_key;
\ No newline at end of file
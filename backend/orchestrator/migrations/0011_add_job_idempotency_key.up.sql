@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN idempotency_key TEXT UNIQUE;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Postgres cannot drop a value from an enum, so CANCELLED stays in job_status;
+-- cancelled jobs are folded into DEAD instead.
+UPDATE jobs SET status = 'DEAD' WHERE status = 'CANCELLED';
+UPDATE job_attempts SET status = 'FAILED' WHERE status = 'CANCELLED';
+
+ALTER TABLE jobs DROP COLUMN cancel_requested;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TYPE job_status ADD VALUE 'CANCELLED';
+
+ALTER TABLE jobs ADD COLUMN cancel_requested BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS job_requeues;
\ No newline at end of file
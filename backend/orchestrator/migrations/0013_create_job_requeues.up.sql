@@ -0,0 +1,12 @@
+CREATE TABLE job_requeues (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  reason TEXT NOT NULL,
+  previous_status job_status NOT NULL,
+  previous_retry_count INT NOT NULL,
+  reset_retries BOOLEAN NOT NULL,
+  extra_retries INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_requeues_job_id ON job_requeues(job_id);
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_jobs_error_trgm;
+DROP INDEX IF EXISTS idx_jobs_updated_at;
+DROP INDEX IF EXISTS idx_jobs_worker_created_at;
+DROP INDEX IF EXISTS idx_jobs_type_created_at;
+DROP INDEX IF EXISTS idx_jobs_status_created_at;
+CREATE INDEX idx_jobs_status ON jobs(status);
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- the composite index covers lookups by status alone
+DROP INDEX IF EXISTS idx_jobs_status;
+CREATE INDEX idx_jobs_status_created_at ON jobs(status, created_at DESC);
+CREATE INDEX idx_jobs_type_created_at ON jobs(type, created_at DESC);
+CREATE INDEX idx_jobs_worker_created_at ON jobs(worker_id, created_at DESC);
+CREATE INDEX idx_jobs_updated_at ON jobs(updated_at DESC);
+
+-- trigram index so ILIKE '%...%' searches on error don't scan the table
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_jobs_error_trgm ON jobs USING gin (error gin_trgm_ops);
\ No newline at end of file
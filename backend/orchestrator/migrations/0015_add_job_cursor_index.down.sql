@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_created_at_id;
+CREATE INDEX idx_jobs_created_at ON jobs(created_at DESC);
\ No newline at end of file
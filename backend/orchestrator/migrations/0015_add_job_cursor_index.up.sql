@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_created_at;
+CREATE INDEX idx_jobs_created_at_id ON jobs(created_at DESC, id DESC);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS job_type_backoff;
+
+ALTER TABLE jobs DROP COLUMN backoff;
\ No newline at end of file
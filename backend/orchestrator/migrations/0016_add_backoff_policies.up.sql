@@ -0,0 +1,7 @@
+ALTER TABLE jobs ADD COLUMN backoff JSONB;
+
+CREATE TABLE job_type_backoff (
+  type TEXT PRIMARY KEY,
+  policy JSONB NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
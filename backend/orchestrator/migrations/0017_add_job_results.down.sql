@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN result_ref;
+ALTER TABLE jobs DROP COLUMN result;
\ No newline at end of file
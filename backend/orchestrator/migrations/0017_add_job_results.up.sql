@@ -0,0 +1,2 @@
+ALTER TABLE jobs ADD COLUMN result JSONB;
+ALTER TABLE jobs ADD COLUMN result_ref TEXT;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Postgres cannot drop a value from an enum, so BLOCKED stays in job_status;
+-- blocked jobs are folded into DEAD instead.
+UPDATE jobs SET status = 'DEAD' WHERE status = 'BLOCKED';
+
+DROP TABLE job_dependencies;
\ No newline at end of file
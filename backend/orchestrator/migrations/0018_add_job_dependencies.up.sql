@@ -0,0 +1,9 @@
+ALTER TYPE job_status ADD VALUE 'BLOCKED';
+
+CREATE TABLE job_dependencies (
+  job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  depends_on UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  PRIMARY KEY (job_id, depends_on)
+);
+
+CREATE INDEX idx_job_dependencies_depends_on ON job_dependencies(depends_on);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE jobs DROP COLUMN workflow_step;
+ALTER TABLE jobs DROP COLUMN workflow_id;
+
+DROP TABLE workflows;
\ No newline at end of file
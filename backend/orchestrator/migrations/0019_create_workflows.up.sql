@@ -0,0 +1,13 @@
+CREATE TABLE workflows (
+  id UUID PRIMARY KEY,
+  name TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'RUNNING',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE jobs ADD COLUMN workflow_id UUID REFERENCES workflows(id);
+ALTER TABLE jobs ADD COLUMN workflow_step TEXT;
+
+-- step names identify the jobs of a workflow
+CREATE UNIQUE INDEX idx_jobs_workflow_step ON jobs(workflow_id, workflow_step) WHERE workflow_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_group_id;
+ALTER TABLE jobs DROP COLUMN group_id;
+
+DROP TABLE job_groups;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE job_groups (
+  id UUID PRIMARY KEY,
+  name TEXT NOT NULL DEFAULT '',
+  total INT NOT NULL,
+  pending INT NOT NULL,
+  succeeded INT NOT NULL DEFAULT 0,
+  dead INT NOT NULL DEFAULT 0,
+  cancelled INT NOT NULL DEFAULT 0,
+  callback_job_id UUID REFERENCES jobs(id) ON DELETE SET NULL,
+  completed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE jobs ADD COLUMN group_id UUID REFERENCES job_groups(id);
+
+CREATE INDEX idx_jobs_group_id ON jobs(group_id) WHERE group_id IS NOT NULL;
\ No newline at end of file
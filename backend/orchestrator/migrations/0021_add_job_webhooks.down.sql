@@ -0,0 +1,4 @@
+DROP TABLE webhook_deliveries;
+
+ALTER TABLE jobs DROP COLUMN callback_secret;
+ALTER TABLE jobs DROP COLUMN callback_url;
\ No newline at end of file
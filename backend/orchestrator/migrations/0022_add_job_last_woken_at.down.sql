@@ -0,0 +1 @@
+ALTER TABLE jobs DROP COLUMN last_woken_at;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN last_woken_at TIMESTAMPTZ;
\ No newline at end of file
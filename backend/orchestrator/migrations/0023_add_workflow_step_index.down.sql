@@ -0,0 +1 @@
+ALTER TABLE jobs DROP COLUMN workflow_step_index;
\ No newline at end of file
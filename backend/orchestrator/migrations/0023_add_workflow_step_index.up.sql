@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN workflow_step_index INT;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE webhook_deliveries DROP COLUMN body;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE webhook_deliveries ADD COLUMN body JSON;
\ No newline at end of file
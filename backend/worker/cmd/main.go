@@ -5,6 +5,9 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -30,12 +33,23 @@ func main() {
 		log.Fatal("Failed to get hostname:", err)
 	}
 
-	workerId, err := client.RegisterWorker(hostname)
+	queues := workerQueues()
+	concurrency := workerConcurrency()
+
+	registry := executor.NewRegistry()
+	executor.RegisterBuiltins(registry)
+
+	workerId, err := client.RegisterWorker(orchestrator.Registration{
+		Hostname: hostname,
+		Queues:   queues,
+		JobTypes: registry.Types(),
+		Slots:    concurrency,
+	})
 	if err != nil {
 		log.Fatal("Failed to register worker:", err)
 	}
 
-	log.Println("Registered worker with ID:", workerId)
+	log.Println("Registered worker with ID:", workerId, "serving queues:", queues, "job types:", registry.Types(), "slots:", concurrency)
 
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -43,58 +57,99 @@ func main() {
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 
-	redisClient := redisclient.New(os.Getenv("REDIS_URL"))
+	redisClient := redisclient.New(os.Getenv("REDIS_URL"), queues, concurrency)
 
-	go func() {
-		for {
+	pullCtx, stopPulling := context.WithCancel(context.Background())
+	jobCtx, abortJobs := context.WithCancel(context.Background())
+	defer abortJobs()
 
-			jobId, err := redisClient.WaitForJob(context.Background())
+	w := &worker{
+		id:       workerId,
+		client:   client,
+		redis:    redisClient,
+		registry: registry,
+		jobCtx:   jobCtx,
+		running:  make(map[string]context.CancelCauseFunc),
+	}
 
-			if err != nil {
-				log.Println("Redis wait failed:", err)
-				continue
-			}
+	var slots sync.WaitGroup
+	for slot := 1; slot <= concurrency; slot++ {
+		slots.Add(1)
+		go func(slot int) {
+			defer slots.Done()
+			w.runSlot(pullCtx, slot)
+		}(slot)
+	}
 
-			log.Println("Woken by Redis for job:", jobId)
-			job, err := client.FetchJob(workerId)
-			if err != nil {
-				log.Println("Failed to fetch job:", err)
-				continue
-			}
-			if job == nil {
-				continue
-			}
-			attempt := job.RetryCount + 1
-			if job.RetryCount > 0 {
-				log.Printf("Retrying job %s (attempt %d/%d)", job.ID, attempt, job.MaxRetries+1)
-			} else {
-				log.Printf("Executing job: %s (max retries: %d)", job.ID, job.MaxRetries)
-			}
-			err = executor.Execute(job.Type)
-			if err != nil {
-				if attempt == job.MaxRetries+1 {
-					log.Printf("Job %s DEAD after %d attempts: %v", job.ID, attempt, err)
-				} else {
-					log.Printf("Job %s FAILED on attempt %d/%d: %v", job.ID, attempt, job.MaxRetries+1, err)
-				}
-				time.Sleep(time.Duration(2^attempt) * 2*time.Second)
-				client.ReportJobResult(job.ID.String(), "FAILED", err.Error())
-			} else {
-				client.ReportJobResult(job.ID.String(), "SUCCESS", "")
-			}
-		}
-	}()
+	// drained stays nil, and so never ready, until a drain has started;
+	// heartbeats keep going meanwhile so the worker isn't marked OFFLINE and
+	// its running jobs reclaimed mid-drain
+	var drained chan struct{}
 
 	for {
 		select {
 		case <-ticker.C:
-			err := client.SendHeartbeat(workerId)
+			hb, err := client.SendHeartbeat(workerId)
 			if err != nil {
 				log.Println("Failed to send heartbeat:", err)
+				continue
+			}
+			for _, jobID := range hb.CancelJobs {
+				w.cancelJob(jobID)
 			}
 		case <-sig:
+			if drained != nil {
+				log.Println("Second signal received, exiting without waiting for jobs")
+				return
+			}
+			grace := drainTimeout()
+			log.Printf("Draining worker (grace period %s)...", grace)
+			drained = make(chan struct{})
+			go func() {
+				w.drain(stopPulling, abortJobs, &slots, grace)
+				close(drained)
+			}()
+		case <-drained:
+			if err := client.DeregisterWorker(workerId); err != nil {
+				log.Println("Failed to deregister worker:", err)
+			}
 			log.Println("Shutting down worker...")
 			return
 		}
 	}
 }
+
+// workerQueues reads the comma-separated WORKER_QUEUES setting, falling back
+// to the default queue.
+func workerQueues() []string {
+	var queues []string
+	for _, q := range strings.Split(os.Getenv("WORKER_QUEUES"), ",") {
+		if q = strings.TrimSpace(q); q != "" {
+			queues = append(queues, q)
+		}
+	}
+	if len(queues) == 0 {
+		queues = []string{"default"}
+	}
+	return queues
+}
+
+// workerConcurrency reads WORKER_CONCURRENCY, the number of jobs this
+// process runs at once. It defaults to 1.
+func workerConcurrency() int {
+	n, err := strconv.Atoi(os.Getenv("WORKER_CONCURRENCY"))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// drainTimeout reads WORKER_DRAIN_TIMEOUT, the number of seconds running jobs
+// get to finish on shutdown. It defaults to 30.
+func drainTimeout() time.Duration {
+	n, err := strconv.Atoi(os.Getenv("WORKER_DRAIN_TIMEOUT"))
+	if err != nil || n < 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(n) * time.Second
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/meanmachine889/distributed-orchestrator/worker/internal/executor"
+	"github.com/meanmachine889/distributed-orchestrator/worker/internal/orchestrator"
+	redisclient "github.com/meanmachine889/distributed-orchestrator/worker/internal/redis"
+)
+
+// abortWait is how long drain waits for aborted jobs to report.
+const abortWait = 10 * time.Second
+
+// errJobCancelled is the cause given to the context of a job cancelled
+// through the API.
+var errJobCancelled = errors.New("job cancelled")
+
+// worker holds what every execution slot of this process shares.
+type worker struct {
+	id       string
+	client   *orchestrator.Client
+	redis    *redisclient.Client
+	registry *executor.Registry
+
+	// jobCtx is the parent of every job's context; it is cancelled when a
+	// drain runs out of grace period.
+	jobCtx context.Context
+
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+}
+
+// runSlot is one execution slot: it waits for a Redis wake-up, fetches a job
+// from the orchestrator and runs it, one job at a time. It returns once ctx
+// is cancelled and the job in hand, if any, has been reported.
+func (w *worker) runSlot(ctx context.Context, slot int) {
+	for {
+
+		jobId, err := w.redis.WaitForJob(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			log.Println("Redis wait failed:", err)
+			continue
+		}
+
+		log.Printf("Slot %d woken by Redis for job: %s", slot, jobId)
+		job, err := w.client.FetchJob(w.id)
+		if err != nil {
+			log.Println("Failed to fetch job:", err)
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		w.runJob(job)
+	}
+}
+
+func (w *worker) runJob(job *orchestrator.JobCreate) {
+	attempt := job.RetryCount + 1
+	if job.RetryCount > 0 {
+		log.Printf("Retrying job %s (attempt %d/%d)", job.ID, attempt, job.MaxRetries+1)
+	} else {
+		log.Printf("Executing job: %s (max retries: %d)", job.ID, job.MaxRetries)
+	}
+	ctx, cancel := w.jobContext(job)
+	result, err := w.registry.Execute(ctx, job.Type, job.Payload)
+	if err == nil && len(result) > 0 && !json.Valid(result) {
+		err = executor.Permanent(errors.New("handler returned an invalid JSON result"))
+	}
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+	aborted := w.jobCtx.Err() != nil
+	cancelled := errors.Is(context.Cause(ctx), errJobCancelled)
+	cancel()
+
+	if err != nil && cancelled {
+		log.Printf("Job %s cancelled: %v", job.ID, err)
+		w.report(orchestrator.Report{JobID: job.ID.String(), WorkerID: w.id, Status: "CANCELLED", Error: "cancelled while running"})
+	} else if err != nil && aborted {
+		log.Printf("Job %s aborted by shutdown: %v", job.ID, err)
+		w.report(orchestrator.Report{JobID: job.ID.String(), WorkerID: w.id, Status: "FAILED", Error: "worker shut down before the job finished"})
+	} else if err != nil {
+		status := "FAILED"
+		if timedOut {
+			status = "TIMEOUT"
+			err = fmt.Errorf("exceeded timeout of %ds", job.TimeoutSeconds)
+		}
+		report := orchestrator.Report{
+			JobID:     job.ID.String(),
+			WorkerID:  w.id,
+			Status:    status,
+			Error:     err.Error(),
+			Permanent: executor.IsPermanent(err),
+		}
+		if delay, ok := executor.RetryDelay(err); ok {
+			// round up so a sub-second delay still waits
+			report.RetryAfterSeconds = int((delay + time.Second - 1) / time.Second)
+		}
+
+		if report.Permanent || attempt == job.MaxRetries+1 {
+			log.Printf("Job %s DEAD after %d attempts: %v", job.ID, attempt, err)
+		} else {
+			log.Printf("Job %s FAILED on attempt %d/%d: %v", job.ID, attempt, job.MaxRetries+1, err)
+		}
+		w.report(report)
+	} else {
+		w.report(orchestrator.Report{
+			JobID:    job.ID.String(),
+			WorkerID: w.id,
+			Status:   "SUCCESS",
+			Result:   result,
+		})
+	}
+}
+
+// report sends the outcome of a job. A report rejected as stale means the
+// job was taken away from this worker, for example after a missed heartbeat,
+// and is only logged.
+func (w *worker) report(report orchestrator.Report) {
+	err := w.client.Report(report)
+	if errors.Is(err, orchestrator.ErrStaleReport) {
+		log.Printf("Report for job %s rejected: %v", report.JobID, err)
+	} else if err != nil {
+		log.Printf("Failed to report job %s: %v", report.JobID, err)
+	}
+}
+
+// jobContext bounds a job by its timeout_seconds; a job without a timeout
+// runs until its handler returns, the worker aborts it or it is cancelled.
+// The returned cancel func must be called once the job is done.
+func (w *worker) jobContext(job *orchestrator.JobCreate) (context.Context, context.CancelFunc) {
+	ctx, cancelJob := context.WithCancelCause(w.jobCtx)
+	id := job.ID.String()
+
+	w.mu.Lock()
+	w.running[id] = cancelJob
+	w.mu.Unlock()
+
+	release := func() {
+		w.mu.Lock()
+		delete(w.running, id)
+		w.mu.Unlock()
+		cancelJob(nil)
+	}
+
+	if job.TimeoutSeconds <= 0 {
+		return ctx, release
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(job.TimeoutSeconds)*time.Second)
+	return ctx, func() {
+		cancel()
+		release()
+	}
+}
+
+// cancelJob cancels the context of a running job. Jobs that are not running
+// here, or have finished meanwhile, are ignored.
+func (w *worker) cancelJob(jobID string) {
+	w.mu.Lock()
+	cancelJob, ok := w.running[jobID]
+	w.mu.Unlock()
+
+	if ok {
+		log.Printf("Cancelling job %s", jobID)
+		cancelJob(errJobCancelled)
+	}
+}
+
+// drain shuts the worker down gracefully: it stops pulling from Redis, marks
+// the worker DRAINING so the orchestrator assigns it nothing new, and waits
+// for the running jobs to finish and report. Jobs still running after grace
+// are cancelled and reported as failed so they are retried elsewhere.
+func (w *worker) drain(stopPulling context.CancelFunc, abortJobs context.CancelFunc, slots *sync.WaitGroup, grace time.Duration) {
+	stopPulling()
+	if err := w.client.DrainWorker(w.id); err != nil {
+		log.Println("Failed to mark worker as draining:", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		slots.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-time.After(grace):
+		log.Printf("Jobs still running after %s, aborting them", grace)
+		abortJobs()
+	}
+
+	// handlers that ignore their context can't be waited on forever
+	select {
+	case <-done:
+	case <-time.After(abortWait):
+		log.Println("Gave up waiting for aborted jobs to report")
+	}
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RegisterBuiltins installs the demo handlers shipped with the worker.
+func RegisterBuiltins(r *Registry) {
+	r.Register("email", sendEmail)
+	r.Register("fail", alwaysFail)
+}
+
+type emailPayload struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+// sendEmail simulates sending an email (2s delay).
+func sendEmail(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+	var p emailPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, Permanent(fmt.Errorf("invalid email payload: %w", err))
+	}
+	if p.To == "" {
+		return nil, Permanent(errors.New("invalid email payload: missing \"to\""))
+	}
+
+	if err := sleep(ctx, 2*time.Second); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]string{"delivered_to": p.To})
+}
+
+// alwaysFail simulates a failing job (1s delay).
+func alwaysFail(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+	if err := sleep(ctx, 1*time.Second); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("simulated job failure")
+}
@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"errors"
+	"time"
+)
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// retryAfterError asks for the next attempt to wait at least delay.
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the job is not retried: it goes straight to
+// DEAD. Use it for errors such as an invalid payload that will fail the same
+// way on every attempt.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryAfter wraps err so that the next attempt waits delay instead of the
+// job's backoff, e.g. when a downstream service says when to come back. The
+// retry still counts against max_retries.
+func RetryAfter(delay time.Duration, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked with
+// Permanent.
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// RetryDelay returns the delay requested with RetryAfter, if any.
+func RetryDelay(err error) (time.Duration, bool) {
+	var re *retryAfterError
+	if errors.As(err, &re) {
+		return re.delay, true
+	}
+	return 0, false
+}
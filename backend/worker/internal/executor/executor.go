@@ -1,20 +1,87 @@
 package executor
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
-func Execute(jobType string) error {
-	switch jobType {
-	case "email":
-		time.Sleep(2 * time.Second)
-		return nil
-	case "fail":
-		time.Sleep(1 * time.Second)
-		return errors.New("simulated job failure")
-	default:
-		time.Sleep(1 * time.Second)
+// ErrUnknownJobType is returned by Execute for a job type with no registered handler.
+var ErrUnknownJobType = errors.New("unknown job type")
+
+// Handler runs a single job. It receives the job's raw JSON payload, should
+// return as soon as ctx is done, and may return a JSON result on success.
+// Errors can be wrapped with Permanent or RetryAfter to control whether and
+// when the job is retried.
+type Handler func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error)
+
+// Registry maps job types to the handlers that run them.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Register installs the handler for jobType. It panics if the type is empty,
+// the handler is nil, or a handler is already registered for the type.
+func (r *Registry) Register(jobType string, h Handler) {
+	if jobType == "" {
+		panic("executor: empty job type")
+	}
+	if h == nil {
+		panic("executor: nil handler for " + jobType)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[jobType]; exists {
+		panic("executor: multiple registrations for " + jobType)
+	}
+	r.handlers[jobType] = h
+}
+
+// Types returns the registered job types in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for t := range r.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Execute runs the handler registered for jobType with the given payload.
+func (r *Registry) Execute(ctx context.Context, jobType string, payload json.RawMessage) (json.RawMessage, error) {
+	r.mu.RLock()
+	h, ok := r.handlers[jobType]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownJobType, jobType)
+	}
+	return h(ctx, payload)
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -3,7 +3,11 @@ package orchestrator
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,6 +22,16 @@ type JobCreate struct {
 	TimeoutSeconds int             `json:"timeout_seconds"`
 }
 
+// Registration describes the worker to the orchestrator: which queues it
+// pulls from, which job types its executor can run and how many jobs it
+// runs at once.
+type Registration struct {
+	Hostname string   `json:"hostname"`
+	Queues   []string `json:"queues"`
+	JobTypes []string `json:"job_types"`
+	Slots    int      `json:"slots"`
+}
+
 type Client struct {
 	baseUrl string
 }
@@ -26,10 +40,8 @@ func New(baseUrl string) *Client {
 	return &Client{baseUrl: baseUrl}
 }
 
-func (c *Client) RegisterWorker(hostname string) (string, error) {
-	body, _ := json.Marshal(map[string]string{
-		"hostname": hostname,
-	})
+func (c *Client) RegisterWorker(reg Registration) (string, error) {
+	body, _ := json.Marshal(reg)
 
 	resp, err := http.Post(c.baseUrl+"/workers/register", "application/json", bytes.NewBuffer(body))
 	if err != nil {
@@ -49,13 +61,60 @@ func (c *Client) RegisterWorker(hostname string) (string, error) {
 	return res.ID, nil
 }
 
-func (c *Client) SendHeartbeat(workerID string) error {
+// HeartbeatResponse is the orchestrator's answer to a heartbeat. CancelJobs
+// lists running jobs of this worker that have been cancelled.
+type HeartbeatResponse struct {
+	Status     string   `json:"status"`
+	CancelJobs []string `json:"cancel_jobs"`
+}
+
+func (c *Client) SendHeartbeat(workerID string) (*HeartbeatResponse, error) {
 	body, _ := json.Marshal(map[string]string{
 		"id": workerID,
 	})
 
-	_, err := http.Post(c.baseUrl+"/workers/heartbeat", "application/json", bytes.NewBuffer(body))
-	return err
+	resp, err := http.Post(c.baseUrl+"/workers/heartbeat", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("heartbeat returned %s", resp.Status)
+	}
+
+	var hb HeartbeatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&hb); err != nil {
+		return nil, err
+	}
+	return &hb, nil
+}
+
+// DrainWorker tells the orchestrator to stop assigning jobs to this worker.
+func (c *Client) DrainWorker(workerID string) error {
+	return c.postWorker("/workers/drain", workerID)
+}
+
+// DeregisterWorker takes this worker out of rotation before it exits.
+func (c *Client) DeregisterWorker(workerID string) error {
+	return c.postWorker("/workers/deregister", workerID)
+}
+
+func (c *Client) postWorker(path string, workerID string) error {
+	body, _ := json.Marshal(map[string]string{
+		"id": workerID,
+	})
+
+	resp, err := http.Post(c.baseUrl+path, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	return nil
 }
 
 func (c *Client) FetchJob(workerID string) (*JobCreate, error) {
@@ -82,13 +141,67 @@ func (c *Client) FetchJob(workerID string) (*JobCreate, error) {
 	return &job, nil
 }
 
-func (c *Client) ReportJobResult(jobID string, status string, errMsg string) error {
-	body, _ := json.Marshal(map[string]string{
-		"job_id": jobID,
-		"status": status,
-		"error":  errMsg,
-	})
+// Report is the outcome of a job sent to /jobs/report by the worker that ran
+// it; the orchestrator rejects reports for jobs the worker no longer runs.
+// Result is the handler's output on success. Permanent and RetryAfterSeconds
+// only apply to failures: the first skips the remaining retries, the second
+// overrides the job's backoff for the next attempt.
+type Report struct {
+	JobID             string          `json:"job_id"`
+	WorkerID          string          `json:"worker_id"`
+	Status            string          `json:"status"`
+	Error             string          `json:"error"`
+	Result            json.RawMessage `json:"result,omitempty"`
+	Permanent         bool            `json:"permanent,omitempty"`
+	RetryAfterSeconds int             `json:"retry_after_seconds,omitempty"`
+}
 
-	_, err := http.Post(c.baseUrl+"/jobs/report", "application/json", bytes.NewBuffer(body))
+// ErrStaleReport is returned by Report when the orchestrator rejected the
+// report because the job no longer runs on this worker.
+var ErrStaleReport = errors.New("job no longer runs on this worker")
+
+// reportAttempts bounds how often a report is sent when the orchestrator
+// can't be reached or fails with a 5xx; a lost report leaves the job RUNNING
+// until it times out.
+const reportAttempts = 3
+
+// Report sends the outcome of a job, retrying a few times on network errors
+// and 5xx replies.
+func (c *Client) Report(report Report) error {
+	body, _ := json.Marshal(report)
+
+	var err error
+	for attempt := 1; attempt <= reportAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * time.Second)
+		}
+
+		var retry bool
+		retry, err = c.postReport(body)
+		if !retry {
+			return err
+		}
+	}
 	return err
 }
+
+func (c *Client) postReport(body []byte) (retry bool, err error) {
+	resp, err := http.Post(c.baseUrl+"/jobs/report", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return false, ErrStaleReport
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("report returned %s", resp.Status)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return false, fmt.Errorf("report returned %s", resp.Status)
+	}
+	return false, nil
+}
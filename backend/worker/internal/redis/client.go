@@ -2,24 +2,64 @@ package redisclient
 
 import (
 	"context"
+	"runtime"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Client struct {
-	rds *redis.Client
+	rds  *redis.Client
+	keys []string
 }
 
-func New(redisUrl string) *Client {
+// New returns a client that waits on the lists of the given queues. Every
+// concurrent WaitForJob holds a connection while blocked, so the pool is
+// sized to fit one per execution slot.
+func New(redisUrl string, queues []string, concurrency int) *Client {
 	opt, _ := redis.ParseURL(redisUrl)
+	opt.PoolSize = max(opt.PoolSize, 10*runtime.GOMAXPROCS(0), concurrency+1)
 	rds := redis.NewClient(opt)
-	return &Client{rds: rds}
+	return &Client{rds: rds, keys: queueKeys(queues)}
 }
 
+// queueKeys mirrors the orchestrator's list layout: the default queue lives
+// at "job_queue", named queues at "queue:<name>", each split into a high,
+// normal and low priority band. Keys are ordered band first so BRPOP picks
+// up urgent jobs from any subscribed queue before the rest.
+func queueKeys(queues []string) []string {
+	var keys []string
+	for _, band := range []string{":high", "", ":low"} {
+		for _, q := range queues {
+			base := "job_queue"
+			if q != "default" {
+				base = "queue:" + q
+			}
+			keys = append(keys, base+band)
+		}
+	}
+	return keys
+}
+
+// waitTimeout bounds each BRPOP so WaitForJob notices a cancelled context
+// without relying on the blocked connection being interrupted.
+const waitTimeout = 5 * time.Second
+
+// WaitForJob blocks until a job id is pushed onto one of the client's lists
+// or ctx is done, in which case it returns ctx.Err().
 func (c *Client) WaitForJob(ctx context.Context) (string, error) {
-	res, err := c.rds.BRPop(ctx, 0, "job_queue").Result()
-	if err != nil {
-		return "", err
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		res, err := c.rds.BRPop(ctx, waitTimeout, c.keys...).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return res[1], nil
 	}
-	return res[1], nil
 }
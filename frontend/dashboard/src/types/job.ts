@@ -3,6 +3,8 @@ export type JobListItem = {
   type: string
   status: string
   retry_count: number
+  queue: string
+  priority: number
   worker_id: string | null
   created_at: string
 }
@@ -15,8 +17,26 @@ export type JobDetail = {
   retry_count: number
   max_retries: number
   timeout_seconds: number
+  queue: string
+  priority: number
   worker_id: string | null
   error?: string
+  run_at: string
+  backoff: any | null
+  result: any | null
+  result_ref: string | null
   created_at: string
   updated_at: string
+  attempts: JobAttempt[]
+}
+
+export type JobAttempt = {
+  attempt_number: number
+  status: string
+  error: string | null
+  worker_id: string | null
+  worker_hostname: string | null
+  started_at: string | null
+  finished_at: string | null
+  duration_ms: number | null
 }
@@ -3,4 +3,9 @@ export type Worker = {
   hostname: string
   status: string
   last_heartbeat: string
+  queues: string[]
+  job_types: string[]
+  slots: number
+  running_jobs: number
+  utilization: number
 }